@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type severity int
+
+const (
+	severityWarning severity = iota
+	severityError
+)
+
+func (s severity) String() string {
+	if s == severityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// diagnostic is a problem found while generating, located at the proto's declaration
+// and the model's field involved if any
+type diagnostic struct {
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Model    string `json:"model,omitempty"` // * file:line of the model's field
+	Message  string `json:"message"`
+}
+
+func (d diagnostic) String() string {
+	var b strings.Builder
+	if d.File != "" {
+		b.WriteString(d.File)
+		if d.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", d.Line, d.Column)
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(d.Severity + ": " + d.Message)
+	if d.Model != "" {
+		b.WriteString(" (model " + d.Model + ")")
+	}
+	return b.String()
+}
+
+// diagnose writes a warning to stderr, as text or a json object per line, where json carries the
+// errors too. Errors fail the generation once it's done, protoc shows them.
+func (p *BimaPlugin) diagnose(sev severity, desc protoreflect.Descriptor, pos token.Pos, format string, args ...interface{}) {
+	d := diagnostic{
+		Severity: sev.String(),
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if desc != nil {
+		d.File = desc.ParentFile().Path()
+		if loc, ok := sourceLocation(desc); ok {
+			d.Line, d.Column = loc.StartLine+1, loc.StartColumn+1
+		}
+	}
+	if pos.IsValid() {
+		position := p.fset.Position(pos)
+		d.Model = fmt.Sprintf("%s:%d", position.Filename, position.Line)
+	}
+	// * Bind and Bundle run into the same problems
+	if p.diagnostics[d] {
+		return
+	}
+	p.diagnostics[d] = true
+
+	w := p.stderr
+	if w == nil {
+		w = os.Stderr
+	}
+	switch {
+	case p.diagnosticsJSON:
+		b, _ := json.Marshal(d)
+		fmt.Fprintln(w, string(b))
+	case sev != severityError:
+		// * protoc prints errors itself
+		fmt.Fprintln(w, d)
+	}
+	if sev == severityError {
+		p.failures = append(p.failures, d.String())
+	}
+}
+
+func sourceLocation(desc protoreflect.Descriptor) (protoreflect.SourceLocation, bool) {
+	path, ok := sourcePath(desc)
+	if !ok {
+		return protoreflect.SourceLocation{}, false
+	}
+	locs := desc.ParentFile().SourceLocations()
+	for i := 0; i < locs.Len(); i++ {
+		if loc := locs.Get(i); equalPath(loc.Path, path) {
+			return loc, true
+		}
+	}
+	return protoreflect.SourceLocation{}, false
+}
+
+// sourcePath builds the path of a declaration from its file, following descriptor.proto's field numbers
+func sourcePath(desc protoreflect.Descriptor) (protoreflect.SourcePath, bool) {
+	parent := desc.Parent()
+	if parent == nil {
+		return nil, true
+	}
+	path, ok := sourcePath(parent)
+	if !ok {
+		return nil, false
+	}
+	_, inFile := parent.(protoreflect.FileDescriptor)
+
+	var number int32
+	switch d := desc.(type) {
+	case protoreflect.MessageDescriptor:
+		number = 3 // * nested_type
+		if inFile {
+			number = 4 // * message_type
+		}
+	case protoreflect.EnumDescriptor:
+		number = 4 // * enum_type
+		if inFile {
+			number = 5
+		}
+	case protoreflect.FieldDescriptor:
+		number = 2 // * field
+		if d.IsExtension() {
+			number = 6
+			if inFile {
+				number = 7
+			}
+		}
+	case protoreflect.OneofDescriptor:
+		number = 8
+	case protoreflect.EnumValueDescriptor, protoreflect.MethodDescriptor:
+		number = 2
+	case protoreflect.ServiceDescriptor:
+		number = 6
+	default:
+		return nil, false
+	}
+	return append(path[:len(path):len(path)], number, int32(desc.Index())), true
+}
+
+func equalPath(a, b protoreflect.SourcePath) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
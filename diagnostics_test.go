@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestSourcePath(t *testing.T) {
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	enum := func(name string, values ...string) *descriptorpb.EnumDescriptorProto {
+		e := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+		for i, value := range values {
+			e.Value = append(e.Value, &descriptorpb.EnumValueDescriptorProto{Name: proto.String(value), Number: proto.Int32(int32(i))})
+		}
+		return e
+	}
+	extension := func(name, extendee string, number int32) *descriptorpb.FieldDescriptorProto {
+		f := field(name, number)
+		f.Extendee = proto.String(extendee)
+		return f
+	}
+	oneof := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.OneofIndex = proto.Int32(0)
+		return f
+	}
+
+	file := newTestFile(t, &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("shop.proto"),
+		Package: proto.String("shop"),
+		Syntax:  proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Empty"),
+				ExtensionRange: []*descriptorpb.DescriptorProto_ExtensionRange{
+					{Start: proto.Int32(100), End: proto.Int32(200)},
+				},
+			},
+			{
+				Name:       proto.String("Order"),
+				Field:      []*descriptorpb.FieldDescriptorProto{field("id", 1), oneof(field("code", 2)), oneof(field("slug", 3))},
+				OneofDecl:  []*descriptorpb.OneofDescriptorProto{{Name: proto.String("ref")}},
+				NestedType: []*descriptorpb.DescriptorProto{{Name: proto.String("Item"), Field: []*descriptorpb.FieldDescriptorProto{field("sku", 1)}}},
+				EnumType:   []*descriptorpb.EnumDescriptorProto{enum("State", "NEW", "PAID")},
+				Extension:  []*descriptorpb.FieldDescriptorProto{extension("note", ".shop.Empty", 101)},
+			},
+		},
+		EnumType:  []*descriptorpb.EnumDescriptorProto{enum("Kind", "DIGITAL", "PHYSICAL")},
+		Extension: []*descriptorpb.FieldDescriptorProto{extension("tag", ".shop.Empty", 100)},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Orders"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("Get"), InputType: proto.String(".shop.Order"), OutputType: proto.String(".shop.Order")},
+				{Name: proto.String("List"), InputType: proto.String(".shop.Empty"), OutputType: proto.String(".shop.Order")},
+			},
+		}},
+	})
+	order := file.Desc.Messages().Get(1)
+
+	tests := []struct {
+		desc protoreflect.Descriptor
+		want protoreflect.SourcePath
+	}{
+		{file.Desc, nil},
+		{order, protoreflect.SourcePath{4, 1}},
+		{order.Fields().Get(2), protoreflect.SourcePath{4, 1, 2, 2}},
+		{order.Oneofs().Get(0), protoreflect.SourcePath{4, 1, 8, 0}},
+		{order.Messages().Get(0), protoreflect.SourcePath{4, 1, 3, 0}},
+		{order.Messages().Get(0).Fields().Get(0), protoreflect.SourcePath{4, 1, 3, 0, 2, 0}},
+		{order.Enums().Get(0), protoreflect.SourcePath{4, 1, 4, 0}},
+		{order.Enums().Get(0).Values().Get(1), protoreflect.SourcePath{4, 1, 4, 0, 2, 1}},
+		{order.Extensions().Get(0), protoreflect.SourcePath{4, 1, 6, 0}},
+		{file.Desc.Enums().Get(0), protoreflect.SourcePath{5, 0}},
+		{file.Desc.Enums().Get(0).Values().Get(1), protoreflect.SourcePath{5, 0, 2, 1}},
+		{file.Desc.Extensions().Get(0), protoreflect.SourcePath{7, 0}},
+		{file.Desc.Services().Get(0), protoreflect.SourcePath{6, 0}},
+		{file.Desc.Services().Get(0).Methods().Get(1), protoreflect.SourcePath{6, 0, 2, 1}},
+	}
+	for _, tt := range tests {
+		got, ok := sourcePath(tt.desc)
+		if !ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sourcePath(%s) = %v, %v, want %v", tt.desc.FullName(), got, ok, tt.want)
+		}
+	}
+
+	// * siblings built from the same parent path don't share its backing array
+	a, _ := sourcePath(order.Fields().Get(0))
+	b, _ := sourcePath(order.Fields().Get(1))
+	if !equalPath(a, protoreflect.SourcePath{4, 1, 2, 0}) || !equalPath(b, protoreflect.SourcePath{4, 1, 2, 1}) {
+		t.Errorf("sibling paths %v and %v", a, b)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		json bool
+		want []string
+	}{
+		// * protoc prints the errors, only warnings are written as text
+		{json: false, want: []string{"warning: field note is skipped"}},
+		{json: true, want: []string{
+			`{"severity":"warning","message":"field note is skipped"}`,
+			`{"severity":"error","message":"model Item not found"}`,
+			`{"severity":"error","message":"model Tag not found"}`,
+		}},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		p := &BimaPlugin{diagnosticsJSON: tt.json, stderr: &b, diagnostics: map[diagnostic]bool{}}
+		p.diagnose(severityWarning, nil, token.NoPos, "field %s is skipped", "note")
+		p.diagnose(severityError, nil, token.NoPos, "model %s not found", "Item")
+		p.diagnose(severityError, nil, token.NoPos, "model %s not found", "Item")
+		p.diagnose(severityError, nil, token.NoPos, "model %s not found", "Tag")
+
+		if got := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("json %v: stderr = %q, want %q", tt.json, got, tt.want)
+		}
+		want := []string{"error: model Item not found", "error: model Tag not found"}
+		if !reflect.DeepEqual(p.failures, want) {
+			t.Errorf("json %v: failures = %q, want %q", tt.json, p.failures, want)
+		}
+	}
+}
@@ -5,5 +5,6 @@ go 1.15
 require (
 	github.com/iancoleman/strcase v0.1.3
 	golang.org/x/mod v0.4.2
+	golang.org/x/tools v0.1.0
 	google.golang.org/protobuf v1.25.0
 )
@@ -4,12 +4,8 @@ import "google.golang.org/protobuf/compiler/protogen"
 
 type fileInfo struct {
 	*protogen.File
-	hasTimestamp bool
 }
 
-func newFileInfo(file *protogen.File, hasTimestamp bool) *fileInfo {
-	f := &fileInfo{File: file}
-	f.hasTimestamp = hasTimestamp
-
-	return f
+func newFileInfo(file *protogen.File) *fileInfo {
+	return &fileInfo{File: file}
 }
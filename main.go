@@ -1,31 +1,120 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 )
 
 func main() {
 	var (
-		flags flag.FlagSet
-		// importPrefix = flags.String("import_prefix", "", "prefix to prepend to import paths")
+		flags          flag.FlagSet
+		importPrefix   = flags.String("import_prefix", "", "prefix to prepend to import paths")
+		versionMarkers = flags.Bool("version_markers", true, "write versions of protoc and protoc-gen-bima in generated files")
+		strict         = flags.Bool("strict", false, "fail on fields which aren't converted between protos and models")
+		diagnostics    = flags.String("diagnostics", "text", "format of diagnostics written to stderr, text or json")
+		modelRoot      = flags.String("model_root", "", "directory models are resolved from, the working directory by default")
+		responseSuffix = flags.String("response_suffix", "Response", "suffix of messages getting response status helpers")
+		grpcErrors     = flags.Bool("grpc_errors", false, "error response helpers return gRPC status errors")
+		dialect        = flags.String("dialect", dialectPostgres, "sql dialect of migrations ("+strings.Join(dialects, ", ")+")")
+		migrationsDir  = flags.String("migrations_dir", "migrations", "directory migrations are read from and written to")
+		modelBase      = flags.String("model_base", "", "struct embedded by generated models, e.g gorm.io/gorm;Model, none when empty")
+		statuses       statusList
+		emit           emitList
 	)
+	flags.Var(&statuses, "statuses", "net/http status of response helpers, repeatable")
+	flags.Var(&emit, "emit", "output to generate ("+strings.Join(emitKinds, ", ")+"), repeatable")
+
 	importRewriteFunc := func(importPath protogen.GoImportPath) protogen.GoImportPath {
-		switch importPath {
-		case "context", "fmt", "math":
+		// * standard library paths have no dot in their first element, e.g net/http
+		if !strings.Contains(strings.SplitN(string(importPath), "/", 2)[0], ".") {
 			return importPath
 		}
-		// if *importPrefix != "" {
-		// 	return protogen.GoImportPath(*importPrefix) + importPath
-		// }
+		if *importPrefix != "" {
+			return protogen.GoImportPath(*importPrefix) + importPath
+		}
 		return importPath
 	}
+	setParam := func(name, value string) error {
+		f := flags.Lookup(name)
+		if f == nil {
+			return errors.New(fmt.Sprintf("unknown parameter %q", name))
+		}
+		// * strict is the same as strict=true
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() && value == "" {
+			value = "true"
+		}
+		if err := flags.Set(name, value); err != nil {
+			return errors.New(fmt.Sprintf("invalid value %q for parameter %s: %v", value, name, err))
+		}
+		return nil
+	}
 	protogen.Options{
-		ParamFunc:         flags.Set,
+		ParamFunc:         setParam,
 		ImportRewriteFunc: importRewriteFunc,
 	}.Run(func(gen *protogen.Plugin) error {
-		BimaPlugin{}.Generate(gen)
+		if *diagnostics != "text" && *diagnostics != "json" {
+			return errors.New(fmt.Sprintf("unknown diagnostics format %q", *diagnostics))
+		}
+		if !contains(dialects, *dialect) {
+			return errors.New(fmt.Sprintf("unknown dialect %q", *dialect))
+		}
+		var base protogen.GoIdent
+		if *modelBase != "" {
+			i := strings.Index(*modelBase, ";")
+			if i < 0 {
+				return errors.New(fmt.Sprintf("model_base %q isn't of the form path;Name", *modelBase))
+			}
+			base = protogen.GoIdent{GoName: (*modelBase)[i+1:], GoImportPath: protogen.GoImportPath((*modelBase)[:i])}
+		}
+		BimaPlugin{
+			versionMarkers:  *versionMarkers,
+			strict:          *strict,
+			diagnosticsJSON: *diagnostics == "json",
+			modelRoot:       *modelRoot,
+			responseSuffix:  *responseSuffix,
+			statuses:        statuses,
+			emit:            emit.kinds(),
+			grpcErrors:      *grpcErrors,
+			dialect:         *dialect,
+			migrationsDir:   *migrationsDir,
+			modelBase:       base,
+		}.Generate(gen)
 		return nil
 	})
 }
+
+// emitList is a repeatable plugin parameter, e.g emit=conversions,emit=responses
+type emitList []string
+
+func (l *emitList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *emitList) Set(kind string) error {
+	if !contains(emitKinds, kind) {
+		return errors.New(fmt.Sprintf("unknown output %s", kind))
+	}
+	*l = append(*l, kind)
+	return nil
+}
+
+func (l emitList) kinds() map[string]bool {
+	kinds := make(map[string]bool, len(l))
+	for _, kind := range l {
+		kinds[kind] = true
+	}
+	return kinds
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
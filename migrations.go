@@ -0,0 +1,531 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// * sql dialects of emit=migrations
+const (
+	dialectPostgres = "postgres"
+	dialectMySQL    = "mysql"
+	dialectSQLite   = "sqlite"
+)
+
+var dialects = []string{dialectPostgres, dialectMySQL, dialectSQLite}
+
+// schemaMarker prefixes the snapshot of the tables a migration leaves, the next migration is diffed against it
+const schemaMarker = "-- bima:schema "
+
+type tableSchema struct {
+	Name    string         `json:"name"`
+	Columns []columnSchema `json:"columns"`
+}
+
+type columnSchema struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Nullable      bool   `json:"nullable,omitempty"`
+	Default       string `json:"default,omitempty"`
+	PrimaryKey    bool   `json:"primary_key,omitempty"`
+	AutoIncrement bool   `json:"auto_increment,omitempty"`
+	Index         bool   `json:"index,omitempty"`
+	Unique        bool   `json:"unique,omitempty"`
+}
+
+// genMigrations writes the next versioned migration of a proto file's tables, e.g migrations/0002_category.sql,
+// creating new tables and altering the ones changed since the last migration
+func (p *BimaPlugin) genMigrations(file *protogen.File) {
+	p.loadSchemas()
+
+	var tables []tableSchema
+	var statements []string
+	for _, m := range file.Messages {
+		model, ok := getModelIdent(m.Desc)
+		if !ok || !p.walkModelFields(m.Desc, model) {
+			continue
+		}
+		table := p.tableSchema(m, model)
+		var s []string
+		if prev, exists := p.schemas[table.Name]; exists {
+			s = p.alterTable(prev, table)
+		} else {
+			s = p.createTable(table)
+		}
+		if len(s) > 0 {
+			tables = append(tables, table)
+			statements = append(statements, s...)
+			p.schemas[table.Name] = table
+		}
+	}
+	if len(statements) == 0 {
+		return
+	}
+
+	p.migrationVersion++
+	name := strcase.ToSnake(strings.TrimSuffix(path.Base(file.Desc.Path()), ".proto"))
+	dir := p.migrationsDir
+	if p.module != "" {
+		// * protogen strips the module from the path, leaving model_root's directory under --bima_out
+		dir = path.Join(p.packageName, dir)
+	}
+	g := p.NewGeneratedFile(path.Join(dir, fmt.Sprintf("%04d_%s.sql", p.migrationVersion, name)), "")
+	snapshot, _ := json.Marshal(tables)
+	g.P("-- Code generated by protoc-gen-bima from ", file.Desc.Path(), ", review before applying.")
+	g.P(schemaMarker, string(snapshot))
+	for _, s := range statements {
+		g.P()
+		g.P(s)
+	}
+}
+
+// loadSchemas reads the snapshots of the migrations already written, in version order
+func (p *BimaPlugin) loadSchemas() {
+	if p.schemas != nil {
+		return
+	}
+	p.schemas = make(map[string]tableSchema)
+
+	dir := filepath.Join(p.modelRoot, p.migrationsDir)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return // * no migration yet
+	}
+	versions := map[string]int{}
+	var names []string
+	for _, info := range infos {
+		i := strings.Index(info.Name(), "_")
+		if info.IsDir() || i < 0 || !strings.HasSuffix(info.Name(), ".sql") {
+			continue
+		}
+		version, err := strconv.Atoi(info.Name()[:i])
+		if err != nil {
+			continue
+		}
+		versions[info.Name()] = version
+		names = append(names, info.Name())
+		if version > p.migrationVersion {
+			p.migrationVersion = version
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return versions[names[i]] < versions[names[j]] })
+
+	for _, name := range names {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			p.diagnose(severityWarning, nil, token.NoPos, "couldn't read migration %s: %s", name, err)
+			continue
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if !strings.HasPrefix(line, schemaMarker) {
+				continue
+			}
+			var tables []tableSchema
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, schemaMarker)), &tables); err != nil {
+				p.diagnose(severityWarning, nil, token.NoPos, "couldn't read the schema of migration %s: %s", name, err)
+				continue
+			}
+			for _, t := range tables {
+				p.schemas[t.Name] = t
+			}
+		}
+	}
+}
+
+// tableSchema describes a model's table the way GORM names it, the proto field's options
+// win over the model's gorm tag
+func (p *BimaPlugin) tableSchema(m *protogen.Message, model protogen.GoIdent) tableSchema {
+	// * bima:"-" fields are still columns, only gorm:"-" ones aren't
+	columns := p.modelColumns[model.GoName]
+	fields := make([]*modelField, 0, len(columns))
+	for _, f := range columns {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return lessIndex(fields[i].index, fields[j].index) })
+	protoFields := map[string]*protogen.Field{}
+	for _, field := range m.Fields {
+		// * paired the way Bind and Bundle do, honoring (gorm.field).model_field
+		f, ok := getModelField(p.modelTypes[model.GoName], field, false)
+		if !ok {
+			f, ok = getModelField(p.modelTypes[model.GoName], field, true)
+		}
+		if ok {
+			protoFields[f.Name()] = field
+		}
+	}
+
+	table := tableSchema{Name: p.tableName(model)}
+	hasPrimaryKey := false
+	for _, f := range fields {
+		if _, ok := f.setting("primaryKey"); ok {
+			hasPrimaryKey = true
+		}
+	}
+	for _, f := range fields {
+		if isRelation(f.Type()) {
+			continue
+		}
+		column := columnSchema{Name: f.column()}
+		if _, ok := f.setting("primaryKey"); ok || !hasPrimaryKey && column.Name == "id" {
+			column.PrimaryKey = true
+		}
+
+		sqlType, nullable, integer := p.columnType(f.Type())
+		if t, ok := f.setting("type"); ok {
+			sqlType = t
+		} else if size, ok := f.setting("size"); ok && sqlType != "" && p.dialect != dialectSQLite {
+			sqlType = fmt.Sprintf("varchar(%s)", size)
+		}
+		if sqlType == "" {
+			p.diagnose(severityWarning, m.Desc, f.Pos(), "column %s of %s has no sql type, set one with gorm:\"type:...\"", column.Name, table.Name)
+			continue
+		}
+		column.Type = sqlType
+		if autoIncrement, ok := f.setting("autoIncrement"); column.PrimaryKey && integer && (!ok || autoIncrement != "false") {
+			column.AutoIncrement = true
+		}
+
+		column.Nullable = nullable && !column.PrimaryKey
+		if _, ok := f.setting("not null"); ok {
+			column.Nullable = false
+		}
+		column.Default, _ = f.setting("default")
+		_, column.Index = f.setting("index")
+		_, unique := f.setting("unique")
+		_, uniqueIndex := f.setting("uniqueIndex")
+		column.Unique = unique || uniqueIndex
+
+		if field, ok := protoFields[f.Name()]; ok {
+			opts := getFieldOptions(field.Desc)
+			if opts != nil && opts.Nullable != nil {
+				column.Nullable = opts.GetNullable()
+			}
+			if opts != nil && opts.DefaultValue != nil {
+				column.Default = opts.GetDefaultValue()
+			}
+			column.Index = column.Index || opts.GetIndex()
+			column.Unique = column.Unique || opts.GetUnique()
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	return table
+}
+
+// tableName is the name TableName() returns or GORM's default, e.g categories
+func (p *BimaPlugin) tableName(model protogen.GoIdent) string {
+	if pkg, ok := p.packages[model.GoImportPath]; ok && pkg != nil {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if name, ok := tableNameLiteral(decl, model.GoName); ok {
+					return name
+				}
+			}
+		}
+	}
+	return pluralize(strcase.ToSnake(model.GoName))
+}
+
+// tableNameLiteral reads `func (Model) TableName() string { return "name" }`
+func tableNameLiteral(decl ast.Decl, model string) (string, bool) {
+	fn, ok := decl.(*ast.FuncDecl)
+	if !ok || fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil || len(fn.Body.List) != 1 {
+		return "", false
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if ident, ok := recv.(*ast.Ident); !ok || ident.Name != model {
+		return "", false
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", false
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	name, err := strconv.Unquote(lit.Value)
+	return name, err == nil
+}
+
+// columnType maps a model field's type to the dialect's, integer tells whether it may auto increment
+func (p *BimaPlugin) columnType(t types.Type) (sqlType string, nullable bool, integer bool) {
+	t, nullable = derefType(t)
+	if v, ok := nullValue(t); ok {
+		t, nullable = v.Type(), true
+	}
+	// * conversions write nil for empty lists, maps and bytes, their driver values are NULL
+	switch t.Underlying().(type) {
+	case *types.Slice, *types.Map, *types.Interface:
+		nullable = true
+	}
+	if typePath(t) == "time.Time" {
+		return map[string]string{
+			dialectPostgres: "timestamptz",
+			dialectMySQL:    "datetime(3)",
+			dialectSQLite:   "datetime",
+		}[p.dialect], nullable, false
+	}
+	if isBytes(t) {
+		return map[string]string{
+			dialectPostgres: "bytea",
+			dialectMySQL:    "longblob",
+			dialectSQLite:   "blob",
+		}[p.dialect], nullable, false
+	}
+	if p.dialect == dialectPostgres {
+		switch typeName(t) {
+		case "pq.StringArray":
+			return "text[]", nullable, false
+		case "pq.Int64Array":
+			return "bigint[]", nullable, false
+		case "pq.Float64Array":
+			return "double precision[]", nullable, false
+		case "pq.BoolArray":
+			return "boolean[]", nullable, false
+		}
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return "", nullable, false
+	}
+	switch basic.Kind() {
+	case types.Bool:
+		if p.dialect == dialectSQLite {
+			return "numeric", nullable, false
+		}
+		return "boolean", nullable, false
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+		return "integer", nullable, true
+	case types.Int, types.Int64, types.Uint, types.Uint32, types.Uint64:
+		if p.dialect == dialectSQLite {
+			return "integer", nullable, true
+		}
+		return "bigint", nullable, true
+	case types.Float32:
+		if p.dialect == dialectMySQL {
+			return "float", nullable, false
+		}
+		return "real", nullable, false
+	case types.Float64:
+		switch p.dialect {
+		case dialectMySQL:
+			return "double", nullable, false
+		case dialectSQLite:
+			return "real", nullable, false
+		}
+		return "double precision", nullable, false
+	case types.String:
+		if p.dialect == dialectMySQL {
+			return "varchar(255)", nullable, false
+		}
+		return "text", nullable, false
+	}
+	return "", nullable, false
+}
+
+func (p *BimaPlugin) createTable(t tableSchema) []string {
+	var defs, keys []string
+	for _, c := range t.Columns {
+		defs = append(defs, p.columnDefinition(c))
+		if c.PrimaryKey && !(p.dialect == dialectSQLite && c.AutoIncrement) {
+			keys = append(keys, p.quote(c.Name))
+		}
+	}
+	if len(keys) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(keys, ", ")+")")
+	}
+	statements := []string{"CREATE TABLE " + p.quote(t.Name) + " (\n\t" + strings.Join(defs, ",\n\t") + "\n);"}
+	for _, c := range t.Columns {
+		if s, ok := p.createIndex(t, c); ok {
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+func (p *BimaPlugin) alterTable(prev tableSchema, t tableSchema) []string {
+	table := p.quote(t.Name)
+	prevColumns := map[string]columnSchema{}
+	for _, c := range prev.Columns {
+		prevColumns[c.Name] = c
+	}
+	columns := map[string]bool{}
+
+	var statements []string
+	for _, c := range t.Columns {
+		columns[c.Name] = true
+		old, exists := prevColumns[c.Name]
+		if !exists {
+			s := "ALTER TABLE " + table + " ADD COLUMN " + p.columnDefinition(c) + ";"
+			if !c.Nullable && c.Default == "" {
+				s = fmt.Sprintf("-- %s is not null without a default, tables with rows need one\n%s", c.Name, s)
+			}
+			statements = append(statements, s)
+			if s, ok := p.createIndex(t, c); ok {
+				statements = append(statements, s)
+			}
+			continue
+		}
+
+		if old.PrimaryKey != c.PrimaryKey || old.AutoIncrement != c.AutoIncrement {
+			statements = append(statements, fmt.Sprintf("-- primary key of %s changed on column %s, it isn't migrated", t.Name, c.Name))
+		}
+		if old.Type != c.Type || old.Nullable != c.Nullable || old.Default != c.Default {
+			statements = append(statements, p.alterColumn(t, old, c)...)
+		}
+		if old.Index != c.Index || old.Unique != c.Unique {
+			if s, ok := p.dropIndex(t, old); ok {
+				statements = append(statements, s)
+			}
+			if s, ok := p.createIndex(t, c); ok {
+				statements = append(statements, s)
+			}
+		}
+	}
+	for _, c := range prev.Columns {
+		if columns[c.Name] {
+			continue
+		}
+		if s, ok := p.dropIndex(t, c); ok {
+			statements = append(statements, s)
+		}
+		statements = append(statements, "ALTER TABLE "+table+" DROP COLUMN "+p.quote(c.Name)+";")
+	}
+	return statements
+}
+
+func (p *BimaPlugin) alterColumn(t tableSchema, old columnSchema, c columnSchema) []string {
+	table, column := p.quote(t.Name), p.quote(c.Name)
+	switch p.dialect {
+	case dialectMySQL:
+		return []string{"ALTER TABLE " + table + " MODIFY COLUMN " + p.columnDefinition(c) + ";"}
+	case dialectSQLite:
+		return []string{fmt.Sprintf("-- sqlite can't alter column %s of %s to %s, the table has to be rebuilt", c.Name, t.Name, p.columnDefinition(c))}
+	}
+
+	var statements []string
+	alter := "ALTER TABLE " + table + " ALTER COLUMN " + column
+	if old.Type != c.Type {
+		statements = append(statements, alter+" TYPE "+c.Type+";")
+	}
+	if old.Nullable != c.Nullable {
+		if c.Nullable {
+			statements = append(statements, alter+" DROP NOT NULL;")
+		} else {
+			statements = append(statements, alter+" SET NOT NULL;")
+		}
+	}
+	if old.Default != c.Default {
+		if c.Default == "" {
+			statements = append(statements, alter+" DROP DEFAULT;")
+		} else {
+			statements = append(statements, alter+" SET DEFAULT "+c.Default+";")
+		}
+	}
+	return statements
+}
+
+func (p *BimaPlugin) columnDefinition(c columnSchema) string {
+	def := p.quote(c.Name) + " " + c.Type
+	if c.AutoIncrement {
+		switch {
+		case p.dialect == dialectPostgres && c.Type == "integer":
+			def = p.quote(c.Name) + " serial"
+		case p.dialect == dialectPostgres && c.Type == "bigint":
+			def = p.quote(c.Name) + " bigserial"
+		case p.dialect == dialectSQLite:
+			return p.quote(c.Name) + " integer PRIMARY KEY AUTOINCREMENT"
+		}
+	}
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	if c.AutoIncrement && p.dialect == dialectMySQL {
+		def += " AUTO_INCREMENT"
+	}
+	return def
+}
+
+// * indexes are named like GORM's, e.g idx_categories_name
+func (p *BimaPlugin) indexName(t tableSchema, c columnSchema) string {
+	return p.quote("idx_" + t.Name + "_" + c.Name)
+}
+
+func (p *BimaPlugin) createIndex(t tableSchema, c columnSchema) (string, bool) {
+	if !c.Index && !c.Unique {
+		return "", false
+	}
+	create := "CREATE INDEX "
+	if c.Unique {
+		create = "CREATE UNIQUE INDEX "
+	}
+	return create + p.indexName(t, c) + " ON " + p.quote(t.Name) + " (" + p.quote(c.Name) + ");", true
+}
+
+func (p *BimaPlugin) dropIndex(t tableSchema, c columnSchema) (string, bool) {
+	if !c.Index && !c.Unique {
+		return "", false
+	}
+	if p.dialect == dialectMySQL {
+		return "DROP INDEX " + p.indexName(t, c) + " ON " + p.quote(t.Name) + ";", true
+	}
+	return "DROP INDEX " + p.indexName(t, c) + ";", true
+}
+
+func (p *BimaPlugin) quote(name string) string {
+	if p.dialect == dialectMySQL {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// isRelation reports a field GORM treats as a relation rather than a column, e.g Author, *Author or []Comment
+func isRelation(t types.Type) bool {
+	if slice, ok := t.Underlying().(*types.Slice); ok {
+		t = slice.Elem()
+	}
+	t, _ = derefType(t)
+	_, isStruct := t.Underlying().(*types.Struct)
+	return isStruct && typePath(t) != "time.Time" && !isScanner(t) && !isValuer(t)
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// pluralize the way GORM names tables, for the common english plurals
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ay") && !strings.HasSuffix(name, "ey") &&
+		!strings.HasSuffix(name, "oy") && !strings.HasSuffix(name, "uy"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") || strings.HasSuffix(name, "z") ||
+		strings.HasSuffix(name, "ch") || strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
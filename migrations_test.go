@@ -0,0 +1,169 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"category":   "categories",
+		"key":        "keys",
+		"day":        "days",
+		"toy":        "toys",
+		"guy":        "guys",
+		"box":        "boxes",
+		"status":     "statuses",
+		"match":      "matches",
+		"dish":       "dishes",
+		"quiz":       "quizes",
+		"order_item": "order_items",
+	}
+	for name, want := range tests {
+		if got := pluralize(name); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAlterTable(t *testing.T) {
+	id := columnSchema{Name: "id", Type: "text", PrimaryKey: true}
+	tests := []struct {
+		name    string
+		dialect string
+		prev    []columnSchema
+		next    []columnSchema
+		want    []string
+	}{
+		{
+			name:    "unchanged",
+			dialect: dialectPostgres,
+			prev:    []columnSchema{id, {Name: "name", Type: "text"}},
+			next:    []columnSchema{id, {Name: "name", Type: "text"}},
+		},
+		{
+			name:    "add nullable column",
+			dialect: dialectPostgres,
+			prev:    []columnSchema{id},
+			next:    []columnSchema{id, {Name: "note", Type: "text", Nullable: true}},
+			want:    []string{`ALTER TABLE "items" ADD COLUMN "note" text;`},
+		},
+		{
+			name:    "add not null column without default",
+			dialect: dialectPostgres,
+			prev:    []columnSchema{id},
+			next:    []columnSchema{id, {Name: "age", Type: "bigint"}},
+			want: []string{"-- age is not null without a default, tables with rows need one\n" +
+				`ALTER TABLE "items" ADD COLUMN "age" bigint NOT NULL;`},
+		},
+		{
+			name:    "add indexed column",
+			dialect: dialectMySQL,
+			prev:    []columnSchema{id},
+			next:    []columnSchema{id, {Name: "slug", Type: "varchar(255)", Default: "''", Unique: true}},
+			want: []string{
+				"ALTER TABLE `items` ADD COLUMN `slug` varchar(255) NOT NULL DEFAULT '';",
+				"CREATE UNIQUE INDEX `idx_items_slug` ON `items` (`slug`);",
+			},
+		},
+		{
+			name:    "drop indexed column",
+			dialect: dialectPostgres,
+			prev:    []columnSchema{id, {Name: "slug", Type: "text", Index: true}},
+			next:    []columnSchema{id},
+			want: []string{
+				`DROP INDEX "idx_items_slug";`,
+				`ALTER TABLE "items" DROP COLUMN "slug";`,
+			},
+		},
+		{
+			name:    "alter column on postgres",
+			dialect: dialectPostgres,
+			prev:    []columnSchema{id, {Name: "score", Type: "integer", Default: "0"}},
+			next:    []columnSchema{id, {Name: "score", Type: "bigint", Nullable: true}},
+			want: []string{
+				`ALTER TABLE "items" ALTER COLUMN "score" TYPE bigint;`,
+				`ALTER TABLE "items" ALTER COLUMN "score" DROP NOT NULL;`,
+				`ALTER TABLE "items" ALTER COLUMN "score" DROP DEFAULT;`,
+			},
+		},
+		{
+			name:    "alter column on mysql",
+			dialect: dialectMySQL,
+			prev:    []columnSchema{id, {Name: "score", Type: "integer"}},
+			next:    []columnSchema{id, {Name: "score", Type: "bigint", Default: "1"}},
+			want:    []string{"ALTER TABLE `items` MODIFY COLUMN `score` bigint NOT NULL DEFAULT 1;"},
+		},
+		{
+			name:    "alter column on sqlite",
+			dialect: dialectSQLite,
+			prev:    []columnSchema{id, {Name: "score", Type: "integer"}},
+			next:    []columnSchema{id, {Name: "score", Type: "integer", Nullable: true}},
+			want:    []string{`-- sqlite can't alter column score of items to "score" integer, the table has to be rebuilt`},
+		},
+		{
+			name:    "index changed",
+			dialect: dialectSQLite,
+			prev:    []columnSchema{id, {Name: "name", Type: "text", Index: true}},
+			next:    []columnSchema{id, {Name: "name", Type: "text", Unique: true}},
+			want: []string{
+				`DROP INDEX "idx_items_name";`,
+				`CREATE UNIQUE INDEX "idx_items_name" ON "items" ("name");`,
+			},
+		},
+		{
+			name:    "primary key changed",
+			dialect: dialectPostgres,
+			prev:    []columnSchema{id},
+			next:    []columnSchema{{Name: "id", Type: "text"}},
+			want:    []string{"-- primary key of items changed on column id, it isn't migrated"},
+		},
+	}
+	for _, tt := range tests {
+		p := &BimaPlugin{dialect: tt.dialect}
+		got := p.alterTable(tableSchema{Name: "items", Columns: tt.prev}, tableSchema{Name: "items", Columns: tt.next})
+		if len(got) != 0 || len(tt.want) != 0 {
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: alterTable() = %q, want %q", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLoadSchemas(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"0001_item.sql": schemaMarker + `[{"name":"items","columns":[{"name":"id","type":"text"}]}]` + "\n\nCREATE TABLE ...;\n",
+		"0010_item.sql": schemaMarker + `[{"name":"items","columns":[{"name":"id","type":"text"},{"name":"name","type":"text"}]}]` + "\n",
+		"0002_tag.sql":  "-- written by hand\n" + schemaMarker + `[{"name":"tags","columns":[{"name":"id","type":"bigint"}]}]` + "\n",
+		"0003_note.sql": "-- no snapshot\n",
+		"notes.txt":     schemaMarker + `[{"name":"ignored","columns":[]}]`,
+		"x_item.sql":    schemaMarker + `[{"name":"ignored","columns":[]}]`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &BimaPlugin{modelRoot: dir, migrationsDir: ".", diagnostics: map[diagnostic]bool{}}
+	p.loadSchemas()
+	if p.migrationVersion != 10 {
+		t.Errorf("migrationVersion = %d, want 10", p.migrationVersion)
+	}
+	want := map[string]tableSchema{
+		"items": {Name: "items", Columns: []columnSchema{{Name: "id", Type: "text"}, {Name: "name", Type: "text"}}},
+		"tags":  {Name: "tags", Columns: []columnSchema{{Name: "id", Type: "bigint"}}},
+	}
+	if !reflect.DeepEqual(p.schemas, want) {
+		t.Errorf("schemas = %+v, want %+v", p.schemas, want)
+	}
+
+	p = &BimaPlugin{modelRoot: dir, migrationsDir: "missing", diagnostics: map[diagnostic]bool{}}
+	p.loadSchemas()
+	if p.migrationVersion != 0 || len(p.schemas) != 0 {
+		t.Errorf("without migrations got version %d and schemas %+v", p.migrationVersion, p.schemas)
+	}
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"golang.org/x/tools/go/packages"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// * model's fields by name, including the ones promoted from embedded structs
+type structFields = map[string]*modelField
+
+type modelField struct {
+	*types.Var
+	tag      reflect.StructTag
+	promoted bool  // * declared on an embedded struct
+	index    []int // * path of the field through embedded structs, in declaration order
+}
+
+// column returns the column name set by gorm's tag, e.g gorm:"column:user_id", or
+// gorm's default naming of the field, e.g UserID is user_id
+func (f *modelField) column() string {
+	if column, _ := f.setting("column"); column != "" {
+		return column
+	}
+	return strcase.ToSnake(f.Name())
+}
+
+// setting returns a setting of gorm's tag, case insensitively, e.g gorm:"size:64" has size 64
+// while flags like gorm:"primaryKey" have an empty value
+func (f *modelField) setting(name string) (string, bool) {
+	for _, setting := range strings.Split(f.tag.Get("gorm"), ";") {
+		kv := strings.SplitN(setting, ":", 2)
+		if !strings.EqualFold(strings.TrimSpace(kv[0]), name) {
+			continue
+		}
+		if len(kv) == 2 {
+			return strings.TrimSpace(kv[1]), true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+func (f *modelField) jsonName() string {
+	if name := strings.Split(f.tag.Get("json"), ",")[0]; name != "-" {
+		return name
+	}
+	return ""
+}
+
+// excluded reports whether the field is left out by gorm:"-" or bima:"-"
+func (f *modelField) excluded() bool {
+	return f.tag.Get("bima") == "-" || f.gormExcluded()
+}
+
+// gormExcluded reports whether the field isn't a column, gorm:"-" or gorm:"-:all"
+func (f *modelField) gormExcluded() bool {
+	for _, setting := range strings.Split(f.tag.Get("gorm"), ";") {
+		if setting = strings.TrimSpace(setting); setting == "-" || setting == "-:all" {
+			return true
+		}
+	}
+	return false
+}
+
+// managed reports whether gorm sets the column itself on create or delete, e.g CreatedAt,
+// gorm:"autoCreateTime" or DeletedAt
+func (f *modelField) managed() bool {
+	if value, ok := f.setting("autoCreateTime"); ok && !strings.EqualFold(value, "false") {
+		return true
+	}
+	return f.Name() == "CreatedAt" || f.Name() == "DeletedAt" || typePath(f.Type()) == "gorm.io/gorm.DeletedAt"
+}
+
+// matchModelField finds the model's field of a proto field by, in order, the bima tag,
+// the go name, gorm's column and the json tag. Fields having a bima tag only match by it.
+func matchModelField(sf structFields, field *protogen.Field) (*modelField, bool) {
+	names := make([]string, 0, len(sf))
+	for name := range sf {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	protoName, jsonName := string(field.Desc.Name()), field.Desc.JSONName()
+	matches := []func(f *modelField) bool{
+		func(f *modelField) bool {
+			name := f.tag.Get("bima")
+			return name != "" && (name == protoName || name == jsonName)
+		},
+		func(f *modelField) bool {
+			return f.Name() == field.GoName
+		},
+		func(f *modelField) bool {
+			return f.column() == protoName
+		},
+		func(f *modelField) bool {
+			return f.jsonName() == protoName || f.jsonName() == jsonName
+		},
+	}
+	for i, match := range matches {
+		for _, name := range names {
+			if f := sf[name]; (i == 0 || f.tag.Get("bima") == "") && match(f) {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (p *BimaPlugin) walkModelFields(desc protoreflect.Descriptor, model protogen.GoIdent) bool {
+	if _, ok := p.modelTypes[model.GoName]; ok {
+		return true
+	}
+
+	pkg, err := p.loadPackage(model.GoImportPath)
+	if err != nil {
+		p.diagnose(severityError, desc, token.NoPos, "%s", err)
+		return false
+	}
+
+	obj, ok := pkg.Types.Scope().Lookup(model.GoName).(*types.TypeName)
+	if !ok {
+		p.modelNotFound(desc, fmt.Sprintf("couldn't find type %s in %s", model.GoName, model.GoImportPath))
+		return false
+	}
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		p.modelNotFound(desc, fmt.Sprintf("type %s in %s is not a struct", model.GoName, model.GoImportPath))
+		return false
+	}
+
+	p.modelTypes[model.GoName] = collectStructFields(obj.Type(), pkg.Types, (*modelField).excluded)
+	p.modelColumns[model.GoName] = collectStructFields(obj.Type(), pkg.Types, (*modelField).gormExcluded)
+	return true
+}
+
+// modelNotFound fails the generation on strict mode, the model's conversions are skipped otherwise
+func (p *BimaPlugin) modelNotFound(desc protoreflect.Descriptor, message string) {
+	sev := severityWarning
+	if p.strict {
+		sev = severityError
+	}
+	p.diagnose(sev, desc, token.NoPos, "%s", message)
+}
+
+// collectStructFields gathers the fields of a struct including the ones promoted from
+// embedded structs (e.g bima.Model), go/types takes care of shadowing and ambiguity.
+// Fields promoted through embedded pointers are skipped since those could be nil, so are
+// the ones exclude reports.
+func collectStructFields(t types.Type, pkg *types.Package, exclude func(*modelField) bool) structFields {
+	names := map[string]bool{}
+	tags := map[*types.Var]reflect.StructTag{}
+	var walk func(t types.Type, visited map[types.Type]bool)
+	walk = func(t types.Type, visited map[types.Type]bool) {
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok || visited[t] {
+			return
+		}
+		visited[t] = true
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			names[f.Name()] = true
+			tags[f] = reflect.StructTag(st.Tag(i))
+			if f.Embedded() {
+				walk(f.Type(), visited)
+			}
+		}
+	}
+	walk(t, map[types.Type]bool{})
+
+	sf := structFields{}
+	for name := range names {
+		obj, index, indirect := types.LookupFieldOrMethod(t, true, pkg, name)
+		if v, ok := obj.(*types.Var); ok && v.IsField() && v.Exported() && !indirect {
+			f := &modelField{Var: v, tag: tags[v], promoted: len(index) > 1, index: index}
+			if !exclude(f) {
+				sf[name] = f
+			}
+		}
+	}
+	return sf
+}
+
+// loadPackage resolves an import path the same way the go command does,
+// honoring go.mod, replace directives, vendor directory and module cache
+func (p *BimaPlugin) loadPackage(importPath protogen.GoImportPath) (*packages.Package, error) {
+	if pkg, ok := p.packages[importPath]; ok {
+		if pkg == nil {
+			return nil, errors.New(fmt.Sprintf("couldn't load package %s", importPath))
+		}
+		return pkg, nil
+	}
+	p.packages[importPath] = nil
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Fset: p.fset,
+		Dir:  p.modelRoot,
+	}, string(importPath))
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, errors.New(fmt.Sprintf("couldn't load package %s", importPath))
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, pkgs[0].Errors[0]
+	}
+	checkPackage(p.fset, pkgs[0], map[string]bool{})
+
+	p.packages[importPath] = pkgs[0]
+	return pkgs[0], nil
+}
+
+// checkPackage type checks a package and its imports from source, go/packages can't read
+// export data of every go release and only the model's declarations matter anyway
+func checkPackage(fset *token.FileSet, pkg *packages.Package, checking map[string]bool) *types.Package {
+	if pkg.Types != nil || checking[pkg.ID] {
+		return pkg.Types
+	}
+	checking[pkg.ID] = true
+
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if imp, ok := pkg.Imports[path]; ok {
+				if t := checkPackage(fset, imp, checking); t != nil {
+					return t, nil
+				}
+			}
+			return nil, errors.New(fmt.Sprintf("couldn't import %s", path))
+		}),
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Sizes:            types.SizesFor("gc", build.Default.GOARCH),
+		Error:            func(error) {}, // * keep going, broken function bodies don't matter
+	}
+	pkg.Types, _ = conf.Check(pkg.PkgPath, fset, pkg.Syntax, nil)
+	return pkg.Types
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// goTypeString renders a model's type to be used in generated code, importing its packages
+func goTypeString(g *protogen.GeneratedFile, t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return strings.TrimSuffix(g.QualifiedGoIdent(protogen.GoIdent{
+			GoImportPath: protogen.GoImportPath(pkg.Path()),
+		}), ".")
+	})
+}
+
+// typePath renders a type qualified by its import path, e.g database/sql.NullString
+func typePath(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Path()
+	})
+}
+
+// typeName renders a type qualified by its package's name, e.g *sql.NullString
+func typeName(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
+
+func derefType(t types.Type) (types.Type, bool) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem(), true
+	}
+	return t, false
+}
+
+// isModelType reports whether a model field's type is the nested model
+func isModelType(t types.Type, nested protogen.GoIdent) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == string(nested.GoImportPath) && named.Obj().Name() == nested.GoName
+}
+
+// nullValue returns the value's field of a sql.NullString like type: a struct of a value
+// and its Valid flag implementing sql.Scanner and driver.Valuer, e.g sql.NullTime or gorm.DeletedAt
+func nullValue(t types.Type) (*types.Var, bool) {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || st.NumFields() != 2 || !isScanner(t) || !isValuer(t) {
+		return nil, false
+	}
+
+	var value *types.Var
+	valid := false
+	for i := 0; i < st.NumFields(); i++ {
+		if f := st.Field(i); f.Name() == "Valid" && isBasicKind(f.Type(), types.IsBoolean) {
+			valid = true
+		} else {
+			value = f
+		}
+	}
+	if !valid || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// isScanner reports whether *t implements sql.Scanner
+func isScanner(t types.Type) bool {
+	fn, ok := lookupMethod(types.NewPointer(t), "Scan")
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 1 && isEmptyInterface(sig.Params().At(0).Type()) &&
+		sig.Results().Len() == 1 && isError(sig.Results().At(0).Type())
+}
+
+// isValuer reports whether t implements driver.Valuer
+func isValuer(t types.Type) bool {
+	fn, ok := lookupMethod(t, "Value")
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 2 &&
+		isEmptyInterface(sig.Results().At(0).Type()) && isError(sig.Results().At(1).Type())
+}
+
+func lookupMethod(t types.Type, name string) (*types.Func, bool) {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, name)
+	fn, ok := obj.(*types.Func)
+	return fn, ok
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isEmptyInterface(t types.Type) bool {
+	it, ok := t.Underlying().(*types.Interface)
+	return ok && it.Empty()
+}
+
+func isBasicKind(t types.Type, info types.BasicInfo) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&info != 0
+}
+
+// isBytes reports whether a type holds raw bytes, e.g []byte, json.RawMessage or datatypes.JSON
+func isBytes(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	return ok && isBasicKind(s.Elem(), types.IsInteger) && s.Elem().Underlying().(*types.Basic).Kind() == types.Byte
+}
+
+func isInterfaceMap(t types.Type) bool {
+	m, ok := t.Underlying().(*types.Map)
+	return ok && types.Identical(m.Key(), types.Typ[types.String]) && isEmptyInterface(m.Elem())
+}
+
+func isInterfaceSlice(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	return ok && isEmptyInterface(s.Elem())
+}
+
+// isConvertible reports whether a proto's scalar type and a model's type convert to each
+// other with a plain go conversion, e.g int32 to int or string to a named string type
+func isConvertible(pbType string, t types.Type) bool {
+	switch {
+	case pbType == "string":
+		return isBasicKind(t, types.IsString)
+	case pbType == "bool":
+		return isBasicKind(t, types.IsBoolean)
+	case pbType == "[]byte":
+		return isBytes(t)
+	case isNumericType(pbType):
+		return isBasicKind(t, types.IsNumeric) && !isBasicKind(t, types.IsComplex)
+	}
+	return false
+}
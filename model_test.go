@@ -0,0 +1,108 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// newTestFile runs protogen over a single proto file
+func newTestFile(t *testing.T, fd *descriptorpb.FileDescriptorProto) *protogen.File {
+	t.Helper()
+	if fd.Options == nil {
+		fd.Options = &descriptorpb.FileOptions{GoPackage: proto.String("example.com/test;test")}
+	}
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.GetName()},
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{fd},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen.FilesByPath[fd.GetName()]
+}
+
+// newTestStruct type checks src and collects the fields of its struct name
+func newTestStruct(t *testing.T, src, name string) structFields {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "model.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("example.com/models", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := pkg.Scope().Lookup(name)
+	if obj == nil {
+		t.Fatalf("%s isn't declared", name)
+	}
+	return collectStructFields(obj.Type(), pkg, (*modelField).excluded)
+}
+
+func TestMatchModelField(t *testing.T) {
+	sf := newTestStruct(t, `package models
+
+type Base struct {
+	ID uint `+"`gorm:\"primarykey\"`"+`
+}
+
+type Item struct {
+	Base
+	UserID string
+	Title  string `+"`bima:\"name\"`"+`
+	Name   string
+	Slug   string `+"`gorm:\"column:url_slug\"`"+`
+	Count  int    `+"`json:\"total\"`"+`
+	Tagged string `+"`bima:\"label\" json:\"note\"`"+`
+	Secret string `+"`bima:\"-\"`"+`
+}
+`, "Item")
+
+	names := []string{"id", "user_id", "name", "url_slug", "total", "label", "display_name", "note", "secret", "missing"}
+	fields := make([]*descriptorpb.FieldDescriptorProto, len(names))
+	for i, name := range names {
+		fields[i] = &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(int32(i + 1)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	fields[6].JsonName = proto.String("label")
+	file := newTestFile(t, &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("item.proto"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Item"), Field: fields}},
+	})
+
+	tests := map[string]string{
+		"id":           "ID",     // * gorm's default column, promoted from Base
+		"user_id":      "UserID", // * gorm's default column
+		"name":         "Title",  // * bima tag wins over the go name
+		"url_slug":     "Slug",   // * gorm column
+		"total":        "Count",  // * json tag
+		"label":        "Tagged", // * bima tag
+		"display_name": "Tagged", // * bima tag by json name
+		"note":         "",       // * tagged fields only match by their bima tag
+		"secret":       "",       // * bima:"-" isn't collected
+		"missing":      "",
+	}
+	for _, field := range file.Messages[0].Fields {
+		want := tests[string(field.Desc.Name())]
+		f, ok := matchModelField(sf, field)
+		if ok != (want != "") || ok && f.Name() != want {
+			t.Errorf("matchModelField(%s) = %v, %v, want %q", field.Desc.Name(), f, ok, want)
+		}
+	}
+}
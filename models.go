@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// * go types of scalar fields on generated models, enums are stored as numbers
+var modelKinds = map[protoreflect.Kind]string{
+	protoreflect.BoolKind:     "bool",
+	protoreflect.EnumKind:     "int32",
+	protoreflect.Int32Kind:    "int32",
+	protoreflect.Sint32Kind:   "int32",
+	protoreflect.Sfixed32Kind: "int32",
+	protoreflect.Uint32Kind:   "uint32",
+	protoreflect.Fixed32Kind:  "uint32",
+	protoreflect.Int64Kind:    "int64",
+	protoreflect.Sint64Kind:   "int64",
+	protoreflect.Sfixed64Kind: "int64",
+	protoreflect.Uint64Kind:   "uint64",
+	protoreflect.Fixed64Kind:  "uint64",
+	protoreflect.FloatKind:    "float32",
+	protoreflect.DoubleKind:   "float64",
+	protoreflect.StringKind:   "string",
+	protoreflect.BytesKind:    "[]byte",
+}
+
+// genModel writes the model (gorm.opts).model names when it doesn't exist yet, so a module can start
+// from its protos, e.g categories/models/category.go. The model is registered as if it was loaded,
+// the next run finds it and leaves it be.
+func (p *BimaPlugin) genModel(file *protogen.File, m *protogen.Message, model protogen.GoIdent) {
+	if _, ok := p.modelTypes[model.GoName]; ok {
+		return
+	}
+	name := regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(path.Base(string(model.GoImportPath)), "_")
+	if pkg, err := p.loadPackage(model.GoImportPath); err == nil {
+		if pkg.Types.Scope().Lookup(model.GoName) != nil {
+			return
+		}
+		name = pkg.Name
+	}
+
+	// * named after the import path like protoc-gen-go does, module= trims it
+	base := strcase.ToSnake(model.GoName) + ".go"
+	filename := path.Join(string(model.GoImportPath), base)
+	if dir := strings.TrimPrefix(string(model.GoImportPath), p.packageName+"/"); p.packageName != "" && dir != string(model.GoImportPath) {
+		if _, err := os.Stat(filepath.Join(p.modelRoot, filepath.FromSlash(dir), base)); err == nil {
+			p.diagnose(severityError, m.Desc, token.NoPos, "model %s can't be generated, %s already exists", model.GoName, path.Join(dir, base))
+			return
+		}
+	}
+
+	pkg := types.NewPackage(string(model.GoImportPath), name)
+	var fields []*types.Var
+	var tags []string
+	embedded := structFields{}
+	baseColumns := map[string]*modelField{}
+	if p.modelBase.GoName != "" {
+		obj, ok := p.lookupModelBase(m.Desc)
+		if !ok {
+			return
+		}
+		fields = append(fields, types.NewField(token.NoPos, pkg, obj.Name(), obj.Type(), true))
+		tags = append(tags, "")
+		embedded = collectStructFields(obj.Type(), obj.Pkg(), (*modelField).excluded)
+		for _, f := range collectStructFields(obj.Type(), obj.Pkg(), (*modelField).gormExcluded) {
+			baseColumns[f.column()] = f
+		}
+	}
+	for _, field := range m.Fields {
+		if getFieldOptions(field.Desc).GetIgnore() {
+			continue
+		}
+		t, ok := p.modelFieldType(field)
+		if !ok {
+			p.diagnose(severityWarning, field.Desc, token.NoPos, "field %s can't be generated on model %s, add it by hand", field.Desc.Name(), model.GoName)
+			continue
+		}
+		goName := modelFieldName(field)
+		// * the base has it already, e.g gorm.Model's CreatedAt
+		if f, ok := embedded[goName]; ok && typePath(f.Type()) == typePath(t) {
+			continue
+		}
+		// * a second field on the base's column would be a duplicate column, e.g gorm.Model's id
+		if f, ok := baseColumns[string(field.Desc.Name())]; ok {
+			if typePath(f.Type()) != typePath(t) {
+				p.diagnose(severityWarning, field.Desc, token.NoPos, "field %s isn't generated on model %s, model base %s has its column as %s %s", field.Desc.Name(), model.GoName, p.modelBase.GoName, f.Name(), typeName(f.Type()))
+			}
+			continue
+		}
+		fields = append(fields, types.NewField(token.NoPos, pkg, goName, t, false))
+		tags = append(tags, modelFieldTag(field))
+	}
+
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, model.GoName, nil), types.NewStruct(fields, tags), nil)
+	p.modelTypes[model.GoName] = collectStructFields(named, pkg, (*modelField).excluded)
+	p.modelColumns[model.GoName] = collectStructFields(named, pkg, (*modelField).gormExcluded)
+
+	g := p.NewGeneratedFile(filename, model.GoImportPath)
+	g.P("// Generated by protoc-gen-bima from ", file.Desc.Path(), ", it's yours to edit and won't be generated again.")
+	g.P()
+	g.P("package ", name)
+	g.P()
+	g.P("type ", model.GoName, " struct {")
+	for i, f := range fields {
+		if f.Embedded() {
+			g.P(goTypeString(g, f.Type()))
+			continue
+		}
+		g.P(f.Name(), " ", goTypeString(g, f.Type()), " `", tags[i], "`")
+	}
+	g.P("}")
+	g.P()
+	g.P("func (", model.GoName, ") TableName() string {")
+	g.P("return ", strconv.Quote(pluralize(strcase.ToSnake(model.GoName))))
+	g.P("}")
+}
+
+func (p *BimaPlugin) lookupModelBase(desc protoreflect.Descriptor) (*types.TypeName, bool) {
+	pkg, err := p.loadPackage(p.modelBase.GoImportPath)
+	if err != nil {
+		p.diagnose(severityError, desc, token.NoPos, "%s", err)
+		return nil, false
+	}
+	obj, ok := pkg.Types.Scope().Lookup(p.modelBase.GoName).(*types.TypeName)
+	if !ok {
+		p.diagnose(severityError, desc, token.NoPos, "couldn't find model base %s in %s", p.modelBase.GoName, p.modelBase.GoImportPath)
+		return nil, false
+	}
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		p.diagnose(severityError, desc, token.NoPos, "model base %s in %s is not a struct", p.modelBase.GoName, p.modelBase.GoImportPath)
+		return nil, false
+	}
+	return obj, true
+}
+
+// modelFieldType is the type a generated model stores a field as, one the conversions support.
+// Lists, maps, oneofs and messages other than well known types have none.
+func (p *BimaPlugin) modelFieldType(field *protogen.Field) (types.Type, bool) {
+	if field.Desc.IsList() || field.Desc.IsMap() || field.Desc.IsWeak() || isOneofField(field) {
+		return nil, false
+	}
+	opts := getFieldOptions(field.Desc)
+	pointer := opts.GetNullable()
+
+	var t types.Type
+	switch {
+	case field.Message == nil:
+		if opts == nil || opts.Nullable == nil {
+			pointer = field.Desc.HasPresence()
+		}
+		t = basicType(modelKinds[field.Desc.Kind()])
+	case field.Message.Desc.FullName() == "google.protobuf.Timestamp":
+		t = p.lookupType("time", "Time")
+	case field.Message.Desc.FullName() == "google.protobuf.Duration":
+		t = p.lookupType("time", "Duration")
+	default:
+		valueType, ok := wellKnownTypes[field.Message.GoIdent.GoName]
+		if !ok {
+			return nil, false
+		}
+		// * wrappers are converted from/to pointers only
+		t, pointer = basicType(valueType), true
+	}
+	if t == nil {
+		return nil, false
+	}
+	if pointer && !isBytes(t) {
+		t = types.NewPointer(t)
+	}
+	return t, true
+}
+
+func (p *BimaPlugin) lookupType(importPath protogen.GoImportPath, name string) types.Type {
+	pkg, err := p.loadPackage(importPath)
+	if err != nil {
+		return nil
+	}
+	obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	return obj.Type()
+}
+
+func basicType(name string) types.Type {
+	if name == "[]byte" {
+		return types.NewSlice(types.Universe.Lookup("byte").Type())
+	}
+	return types.Universe.Lookup(name).Type()
+}
+
+// modelFieldName is the go name of a field on a generated model, (gorm.field).model_field or
+// the proto name in go's casing, e.g category_id is CategoryID
+func modelFieldName(field *protogen.Field) string {
+	if name := getFieldOptions(field.Desc).GetModelField(); name != "" {
+		return name
+	}
+	name := strcase.ToCamel(string(field.Desc.Name()))
+	if strings.HasSuffix(name, "Id") {
+		name = strings.TrimSuffix(name, "Id") + "ID"
+	}
+	return name
+}
+
+// modelFieldTag maps the field to its column, along with the column's options, e.g
+// gorm:"column:slug;unique" json:"slug"
+func modelFieldTag(field *protogen.Field) string {
+	settings := []string{"column:" + string(field.Desc.Name())}
+	if field.Desc.Name() == "id" {
+		settings = append(settings, "primaryKey")
+	}
+	opts := getFieldOptions(field.Desc)
+	if opts.GetIndex() {
+		settings = append(settings, "index")
+	}
+	if opts.GetUnique() {
+		settings = append(settings, "unique")
+	}
+	if opts != nil && opts.DefaultValue != nil {
+		settings = append(settings, "default:"+opts.GetDefaultValue())
+	}
+	return fmt.Sprintf(`gorm:"%s" json:"%s"`, strings.Join(settings, ";"), field.Desc.Name())
+}
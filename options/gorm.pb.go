@@ -21,6 +21,194 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type GormFieldOptions_EnumCase int32
+
+const (
+	// keep the enum value name as declared, e.g STATUS_ACTIVE
+	GormFieldOptions_ORIGINAL GormFieldOptions_EnumCase = 0
+	// lower case the enum value name, e.g status_active
+	GormFieldOptions_LOWER GormFieldOptions_EnumCase = 1
+	// snake case the enum value name, e.g InProgress becomes in_progress
+	GormFieldOptions_SNAKE GormFieldOptions_EnumCase = 2
+)
+
+// Enum value maps for GormFieldOptions_EnumCase.
+var (
+	GormFieldOptions_EnumCase_name = map[int32]string{
+		0: "ORIGINAL",
+		1: "LOWER",
+		2: "SNAKE",
+	}
+	GormFieldOptions_EnumCase_value = map[string]int32{
+		"ORIGINAL": 0,
+		"LOWER":    1,
+		"SNAKE":    2,
+	}
+)
+
+func (x GormFieldOptions_EnumCase) Enum() *GormFieldOptions_EnumCase {
+	p := new(GormFieldOptions_EnumCase)
+	*p = x
+	return p
+}
+
+func (x GormFieldOptions_EnumCase) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GormFieldOptions_EnumCase) Descriptor() protoreflect.EnumDescriptor {
+	return file_options_gorm_proto_enumTypes[0].Descriptor()
+}
+
+func (GormFieldOptions_EnumCase) Type() protoreflect.EnumType {
+	return &file_options_gorm_proto_enumTypes[0]
+}
+
+func (x GormFieldOptions_EnumCase) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *GormFieldOptions_EnumCase) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = GormFieldOptions_EnumCase(num)
+	return nil
+}
+
+// Deprecated: Use GormFieldOptions_EnumCase.Descriptor instead.
+func (GormFieldOptions_EnumCase) EnumDescriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type GormFieldOptions_DurationUnit int32
+
+const (
+	GormFieldOptions_MILLISECONDS GormFieldOptions_DurationUnit = 0
+	GormFieldOptions_SECONDS      GormFieldOptions_DurationUnit = 1
+)
+
+// Enum value maps for GormFieldOptions_DurationUnit.
+var (
+	GormFieldOptions_DurationUnit_name = map[int32]string{
+		0: "MILLISECONDS",
+		1: "SECONDS",
+	}
+	GormFieldOptions_DurationUnit_value = map[string]int32{
+		"MILLISECONDS": 0,
+		"SECONDS":      1,
+	}
+)
+
+func (x GormFieldOptions_DurationUnit) Enum() *GormFieldOptions_DurationUnit {
+	p := new(GormFieldOptions_DurationUnit)
+	*p = x
+	return p
+}
+
+func (x GormFieldOptions_DurationUnit) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GormFieldOptions_DurationUnit) Descriptor() protoreflect.EnumDescriptor {
+	return file_options_gorm_proto_enumTypes[1].Descriptor()
+}
+
+func (GormFieldOptions_DurationUnit) Type() protoreflect.EnumType {
+	return &file_options_gorm_proto_enumTypes[1]
+}
+
+func (x GormFieldOptions_DurationUnit) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *GormFieldOptions_DurationUnit) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = GormFieldOptions_DurationUnit(num)
+	return nil
+}
+
+// Deprecated: Use GormFieldOptions_DurationUnit.Descriptor instead.
+func (GormFieldOptions_DurationUnit) EnumDescriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{1, 1}
+}
+
+type GormMethodOptions_Action int32
+
+const (
+	// responds StatusCreated with the created message
+	GormMethodOptions_CREATE GormMethodOptions_Action = 0
+	// responds StatusOK with the message found by id
+	GormMethodOptions_GET GormMethodOptions_Action = 1
+	// responds StatusOK with the updated message
+	GormMethodOptions_UPDATE GormMethodOptions_Action = 2
+	// responds StatusNoContent
+	GormMethodOptions_DELETE GormMethodOptions_Action = 3
+	// responds StatusOK with a page of messages
+	GormMethodOptions_LIST GormMethodOptions_Action = 4
+)
+
+// Enum value maps for GormMethodOptions_Action.
+var (
+	GormMethodOptions_Action_name = map[int32]string{
+		0: "CREATE",
+		1: "GET",
+		2: "UPDATE",
+		3: "DELETE",
+		4: "LIST",
+	}
+	GormMethodOptions_Action_value = map[string]int32{
+		"CREATE": 0,
+		"GET":    1,
+		"UPDATE": 2,
+		"DELETE": 3,
+		"LIST":   4,
+	}
+)
+
+func (x GormMethodOptions_Action) Enum() *GormMethodOptions_Action {
+	p := new(GormMethodOptions_Action)
+	*p = x
+	return p
+}
+
+func (x GormMethodOptions_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GormMethodOptions_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_options_gorm_proto_enumTypes[2].Descriptor()
+}
+
+func (GormMethodOptions_Action) Type() protoreflect.EnumType {
+	return &file_options_gorm_proto_enumTypes[2]
+}
+
+func (x GormMethodOptions_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *GormMethodOptions_Action) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = GormMethodOptions_Action(num)
+	return nil
+}
+
+// Deprecated: Use GormMethodOptions_Action.Descriptor instead.
+func (GormMethodOptions_Action) EnumDescriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{3, 0}
+}
+
 type GormMessageOptions struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -68,6 +256,313 @@ func (x *GormMessageOptions) GetModel() string {
 	return ""
 }
 
+type GormFieldOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// model's field name when it differs from the proto field's Go name
+	ModelField *string `protobuf:"bytes,1,opt,name=model_field,json=modelField" json:"model_field,omitempty"`
+	// skip the field on both Bind and Bundle
+	Ignore *bool `protobuf:"varint,2,opt,name=ignore" json:"ignore,omitempty"`
+	// skip the field on Bind, the model only feeds it on Bundle
+	Readonly *bool `protobuf:"varint,3,opt,name=readonly" json:"readonly,omitempty"`
+	// skip the field on Bundle, the model is only fed on Bind
+	Writeonly *bool `protobuf:"varint,4,opt,name=writeonly" json:"writeonly,omitempty"`
+	// how an enum is stored when the model's field is a string
+	EnumCase *GormFieldOptions_EnumCase `protobuf:"varint,5,opt,name=enum_case,json=enumCase,enum=gorm.GormFieldOptions_EnumCase" json:"enum_case,omitempty"`
+	// unit of a Duration stored in an integer or sql.NullInt64 column
+	DurationUnit *GormFieldOptions_DurationUnit `protobuf:"varint,6,opt,name=duration_unit,json=durationUnit,enum=gorm.GormFieldOptions_DurationUnit" json:"duration_unit,omitempty"`
+	// index the column on emit=migrations
+	Index *bool `protobuf:"varint,7,opt,name=index" json:"index,omitempty"`
+	// unique index the column on emit=migrations
+	Unique *bool `protobuf:"varint,8,opt,name=unique" json:"unique,omitempty"`
+	// column's default on emit=migrations, an sql expression, e.g 'draft' or now()
+	DefaultValue *string `protobuf:"bytes,9,opt,name=default_value,json=defaultValue" json:"default_value,omitempty"`
+	// allow NULL on emit=migrations, only pointers and sql.NullString like fields are nullable otherwise
+	Nullable *bool `protobuf:"varint,10,opt,name=nullable" json:"nullable,omitempty"`
+}
+
+func (x *GormFieldOptions) Reset() {
+	*x = GormFieldOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_options_gorm_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GormFieldOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GormFieldOptions) ProtoMessage() {}
+
+func (x *GormFieldOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_options_gorm_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GormFieldOptions.ProtoReflect.Descriptor instead.
+func (*GormFieldOptions) Descriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GormFieldOptions) GetModelField() string {
+	if x != nil && x.ModelField != nil {
+		return *x.ModelField
+	}
+	return ""
+}
+
+func (x *GormFieldOptions) GetIgnore() bool {
+	if x != nil && x.Ignore != nil {
+		return *x.Ignore
+	}
+	return false
+}
+
+func (x *GormFieldOptions) GetReadonly() bool {
+	if x != nil && x.Readonly != nil {
+		return *x.Readonly
+	}
+	return false
+}
+
+func (x *GormFieldOptions) GetWriteonly() bool {
+	if x != nil && x.Writeonly != nil {
+		return *x.Writeonly
+	}
+	return false
+}
+
+func (x *GormFieldOptions) GetEnumCase() GormFieldOptions_EnumCase {
+	if x != nil && x.EnumCase != nil {
+		return *x.EnumCase
+	}
+	return GormFieldOptions_ORIGINAL
+}
+
+func (x *GormFieldOptions) GetDurationUnit() GormFieldOptions_DurationUnit {
+	if x != nil && x.DurationUnit != nil {
+		return *x.DurationUnit
+	}
+	return GormFieldOptions_MILLISECONDS
+}
+
+func (x *GormFieldOptions) GetIndex() bool {
+	if x != nil && x.Index != nil {
+		return *x.Index
+	}
+	return false
+}
+
+func (x *GormFieldOptions) GetUnique() bool {
+	if x != nil && x.Unique != nil {
+		return *x.Unique
+	}
+	return false
+}
+
+func (x *GormFieldOptions) GetDefaultValue() string {
+	if x != nil && x.DefaultValue != nil {
+		return *x.DefaultValue
+	}
+	return ""
+}
+
+func (x *GormFieldOptions) GetNullable() bool {
+	if x != nil && x.Nullable != nil {
+		return *x.Nullable
+	}
+	return false
+}
+
+// response status helpers generated for a response message, or every response message
+// of a file; the message's options win over the file's and both over plugin parameters
+type GormResponseOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// net/http status constants, e.g StatusConflict
+	Statuses []string `protobuf:"bytes,1,rep,name=statuses" json:"statuses,omitempty"`
+	// statuses not declared by net/http
+	Custom []*GormResponseOptions_Status `protobuf:"bytes,2,rep,name=custom" json:"custom,omitempty"`
+}
+
+func (x *GormResponseOptions) Reset() {
+	*x = GormResponseOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_options_gorm_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GormResponseOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GormResponseOptions) ProtoMessage() {}
+
+func (x *GormResponseOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_options_gorm_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GormResponseOptions.ProtoReflect.Descriptor instead.
+func (*GormResponseOptions) Descriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GormResponseOptions) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *GormResponseOptions) GetCustom() []*GormResponseOptions_Status {
+	if x != nil {
+		return x.Custom
+	}
+	return nil
+}
+
+// CRUD semantics of an rpc, the generated handler follows them instead of the rpc's name
+type GormMethodOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Action *GormMethodOptions_Action `protobuf:"varint,1,req,name=action,enum=gorm.GormMethodOptions_Action" json:"action,omitempty"`
+	// request's field holding the id on GET and DELETE
+	IdField *string `protobuf:"bytes,2,opt,name=id_field,json=idField,def=id" json:"id_field,omitempty"`
+}
+
+// Default values for GormMethodOptions fields.
+const (
+	Default_GormMethodOptions_IdField = string("id")
+)
+
+func (x *GormMethodOptions) Reset() {
+	*x = GormMethodOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_options_gorm_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GormMethodOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GormMethodOptions) ProtoMessage() {}
+
+func (x *GormMethodOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_options_gorm_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GormMethodOptions.ProtoReflect.Descriptor instead.
+func (*GormMethodOptions) Descriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GormMethodOptions) GetAction() GormMethodOptions_Action {
+	if x != nil && x.Action != nil {
+		return *x.Action
+	}
+	return GormMethodOptions_CREATE
+}
+
+func (x *GormMethodOptions) GetIdField() string {
+	if x != nil && x.IdField != nil {
+		return *x.IdField
+	}
+	return Default_GormMethodOptions_IdField
+}
+
+type GormResponseOptions_Status struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// http status code, e.g 499
+	Code *int32 `protobuf:"varint,1,req,name=code" json:"code,omitempty"`
+	// name of the helper, e.g StatusClientClosedRequest
+	Name *string `protobuf:"bytes,2,req,name=name" json:"name,omitempty"`
+}
+
+func (x *GormResponseOptions_Status) Reset() {
+	*x = GormResponseOptions_Status{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_options_gorm_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GormResponseOptions_Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GormResponseOptions_Status) ProtoMessage() {}
+
+func (x *GormResponseOptions_Status) ProtoReflect() protoreflect.Message {
+	mi := &file_options_gorm_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GormResponseOptions_Status.ProtoReflect.Descriptor instead.
+func (*GormResponseOptions_Status) Descriptor() ([]byte, []int) {
+	return file_options_gorm_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *GormResponseOptions_Status) GetCode() int32 {
+	if x != nil && x.Code != nil {
+		return *x.Code
+	}
+	return 0
+}
+
+func (x *GormResponseOptions_Status) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
 var file_options_gorm_proto_extTypes = []protoimpl.ExtensionInfo{
 	{
 		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
@@ -77,12 +572,64 @@ var file_options_gorm_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,52119,opt,name=opts",
 		Filename:      "options/gorm.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*GormFieldOptions)(nil),
+		Field:         52120,
+		Name:          "gorm.field",
+		Tag:           "bytes,52120,opt,name=field",
+		Filename:      "options/gorm.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*GormResponseOptions)(nil),
+		Field:         52121,
+		Name:          "gorm.response",
+		Tag:           "bytes,52121,opt,name=response",
+		Filename:      "options/gorm.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*GormResponseOptions)(nil),
+		Field:         52122,
+		Name:          "gorm.file_response",
+		Tag:           "bytes,52122,opt,name=file_response",
+		Filename:      "options/gorm.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*GormMethodOptions)(nil),
+		Field:         52123,
+		Name:          "gorm.method",
+		Tag:           "bytes,52123,opt,name=method",
+		Filename:      "options/gorm.proto",
+	},
 }
 
 // Extension fields to descriptorpb.MessageOptions.
 var (
 	// optional gorm.GormMessageOptions opts = 52119;
 	E_Opts = &file_options_gorm_proto_extTypes[0]
+	// optional gorm.GormResponseOptions response = 52121;
+	E_Response = &file_options_gorm_proto_extTypes[2]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional gorm.GormFieldOptions field = 52120;
+	E_Field = &file_options_gorm_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.FileOptions.
+var (
+	// optional gorm.GormResponseOptions file_response = 52122;
+	E_FileResponse = &file_options_gorm_proto_extTypes[3]
+)
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional gorm.GormMethodOptions method = 52123;
+	E_Method = &file_options_gorm_proto_extTypes[4]
 )
 
 var File_options_gorm_proto protoreflect.FileDescriptor
@@ -94,15 +641,87 @@ var file_options_gorm_proto_rawDesc = []byte{
 	0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2a, 0x0a, 0x12,
 	0x47, 0x6f, 0x72, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f,
 	0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x3a, 0x4f, 0x0a, 0x04, 0x6f, 0x70, 0x74, 0x73,
+	0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x22, 0xdb, 0x03, 0x0a, 0x10, 0x47, 0x6f, 0x72,
+	0x6d, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x61, 0x64, 0x6f, 0x6e,
+	0x6c, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x6f, 0x6e,
+	0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x77, 0x72, 0x69, 0x74, 0x65, 0x6f, 0x6e, 0x6c, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x77, 0x72, 0x69, 0x74, 0x65, 0x6f, 0x6e, 0x6c, 0x79,
+	0x12, 0x3c, 0x0a, 0x09, 0x65, 0x6e, 0x75, 0x6d, 0x5f, 0x63, 0x61, 0x73, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72, 0x6d, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x45, 0x6e, 0x75, 0x6d,
+	0x43, 0x61, 0x73, 0x65, 0x52, 0x08, 0x65, 0x6e, 0x75, 0x6d, 0x43, 0x61, 0x73, 0x65, 0x12, 0x48,
+	0x0a, 0x0d, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72,
+	0x6d, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x0c, 0x64, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x16,
+	0x0a, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
+	0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64,
+	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6e,
+	0x75, 0x6c, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6e,
+	0x75, 0x6c, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x2e, 0x0a, 0x08, 0x45, 0x6e, 0x75, 0x6d, 0x43,
+	0x61, 0x73, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x4f, 0x52, 0x49, 0x47, 0x49, 0x4e, 0x41, 0x4c, 0x10,
+	0x00, 0x12, 0x09, 0x0a, 0x05, 0x4c, 0x4f, 0x57, 0x45, 0x52, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05,
+	0x53, 0x4e, 0x41, 0x4b, 0x45, 0x10, 0x02, 0x22, 0x2d, 0x0a, 0x0c, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x10, 0x0a, 0x0c, 0x4d, 0x49, 0x4c, 0x4c, 0x49,
+	0x53, 0x45, 0x43, 0x4f, 0x4e, 0x44, 0x53, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x45, 0x43,
+	0x4f, 0x4e, 0x44, 0x53, 0x10, 0x01, 0x22, 0x9d, 0x01, 0x0a, 0x13, 0x47, 0x6f, 0x72, 0x6d, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x06, 0x63, 0x75,
+	0x73, 0x74, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x67, 0x6f, 0x72,
+	0x6d, 0x2e, 0x47, 0x6f, 0x72, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x63, 0x75,
+	0x73, 0x74, 0x6f, 0x6d, 0x1a, 0x30, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xab, 0x01, 0x0a, 0x11, 0x47, 0x6f, 0x72, 0x6d, 0x4d,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x36, 0x0a, 0x06,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x67,
+	0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72, 0x6d, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x08, 0x69, 0x64, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x3a, 0x02, 0x69, 0x64, 0x52, 0x07, 0x69, 0x64, 0x46, 0x69,
+	0x65, 0x6c, 0x64, 0x22, 0x3f, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a,
+	0x06, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x47, 0x45, 0x54,
+	0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a,
+	0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x4c, 0x49,
+	0x53, 0x54, 0x10, 0x04, 0x3a, 0x4f, 0x0a, 0x04, 0x6f, 0x70, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x97, 0x97,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72,
+	0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x04, 0x6f, 0x70, 0x74, 0x73, 0x3a, 0x4d, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1d,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x98, 0x97,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72,
+	0x6d, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x05, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x3a, 0x58, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
 	0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
 	0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0x97, 0x97, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x72, 0x6d,
-	0x2e, 0x47, 0x6f, 0x72, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x52, 0x04, 0x6f, 0x70, 0x74, 0x73, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x65, 0x63, 0x6f,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x62, 0x69, 0x6d, 0x61,
-	0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x73, 0x18, 0x99, 0x97, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x72, 0x6d,
+	0x2e, 0x47, 0x6f, 0x72, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x3a, 0x5e,
+	0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x9a, 0x97,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72,
+	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x3a, 0x51,
+	0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x9b, 0x97, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x67, 0x6f, 0x72, 0x6d, 0x2e, 0x47, 0x6f, 0x72, 0x6d, 0x4d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x63, 0x72, 0x6f, 0x77, 0x64, 0x65, 0x63, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d,
+	0x67, 0x65, 0x6e, 0x2d, 0x62, 0x69, 0x6d, 0x61, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
 }
 
 var (
@@ -117,19 +736,42 @@ func file_options_gorm_proto_rawDescGZIP() []byte {
 	return file_options_gorm_proto_rawDescData
 }
 
-var file_options_gorm_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_options_gorm_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_options_gorm_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_options_gorm_proto_goTypes = []interface{}{
-	(*GormMessageOptions)(nil),          // 0: gorm.GormMessageOptions
-	(*descriptorpb.MessageOptions)(nil), // 1: google.protobuf.MessageOptions
+	(GormFieldOptions_EnumCase)(0),      // 0: gorm.GormFieldOptions.EnumCase
+	(GormFieldOptions_DurationUnit)(0),  // 1: gorm.GormFieldOptions.DurationUnit
+	(GormMethodOptions_Action)(0),       // 2: gorm.GormMethodOptions.Action
+	(*GormMessageOptions)(nil),          // 3: gorm.GormMessageOptions
+	(*GormFieldOptions)(nil),            // 4: gorm.GormFieldOptions
+	(*GormResponseOptions)(nil),         // 5: gorm.GormResponseOptions
+	(*GormMethodOptions)(nil),           // 6: gorm.GormMethodOptions
+	(*GormResponseOptions_Status)(nil),  // 7: gorm.GormResponseOptions.Status
+	(*descriptorpb.MessageOptions)(nil), // 8: google.protobuf.MessageOptions
+	(*descriptorpb.FieldOptions)(nil),   // 9: google.protobuf.FieldOptions
+	(*descriptorpb.FileOptions)(nil),    // 10: google.protobuf.FileOptions
+	(*descriptorpb.MethodOptions)(nil),  // 11: google.protobuf.MethodOptions
 }
 var file_options_gorm_proto_depIdxs = []int32{
-	1, // 0: gorm.opts:extendee -> google.protobuf.MessageOptions
-	0, // 1: gorm.opts:type_name -> gorm.GormMessageOptions
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	1, // [1:2] is the sub-list for extension type_name
-	0, // [0:1] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: gorm.GormFieldOptions.enum_case:type_name -> gorm.GormFieldOptions.EnumCase
+	1,  // 1: gorm.GormFieldOptions.duration_unit:type_name -> gorm.GormFieldOptions.DurationUnit
+	7,  // 2: gorm.GormResponseOptions.custom:type_name -> gorm.GormResponseOptions.Status
+	2,  // 3: gorm.GormMethodOptions.action:type_name -> gorm.GormMethodOptions.Action
+	8,  // 4: gorm.opts:extendee -> google.protobuf.MessageOptions
+	9,  // 5: gorm.field:extendee -> google.protobuf.FieldOptions
+	8,  // 6: gorm.response:extendee -> google.protobuf.MessageOptions
+	10, // 7: gorm.file_response:extendee -> google.protobuf.FileOptions
+	11, // 8: gorm.method:extendee -> google.protobuf.MethodOptions
+	3,  // 9: gorm.opts:type_name -> gorm.GormMessageOptions
+	4,  // 10: gorm.field:type_name -> gorm.GormFieldOptions
+	5,  // 11: gorm.response:type_name -> gorm.GormResponseOptions
+	5,  // 12: gorm.file_response:type_name -> gorm.GormResponseOptions
+	6,  // 13: gorm.method:type_name -> gorm.GormMethodOptions
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	9,  // [9:14] is the sub-list for extension type_name
+	4,  // [4:9] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_options_gorm_proto_init() }
@@ -150,19 +792,68 @@ func file_options_gorm_proto_init() {
 				return nil
 			}
 		}
+		file_options_gorm_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GormFieldOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_options_gorm_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GormResponseOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_options_gorm_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GormMethodOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_options_gorm_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GormResponseOptions_Status); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_options_gorm_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   1,
-			NumExtensions: 1,
+			NumEnums:      3,
+			NumMessages:   5,
+			NumExtensions: 5,
 			NumServices:   0,
 		},
 		GoTypes:           file_options_gorm_proto_goTypes,
 		DependencyIndexes: file_options_gorm_proto_depIdxs,
+		EnumInfos:         file_options_gorm_proto_enumTypes,
 		MessageInfos:      file_options_gorm_proto_msgTypes,
 		ExtensionInfos:    file_options_gorm_proto_extTypes,
 	}.Build()
@@ -0,0 +1,75 @@
+package paginator
+
+// Paginator describes the page a paginated response carries, generated helpers copy it
+// into the response's meta or pagination field
+type Paginator interface {
+	Page() int
+	Limit() int
+	Total() int64
+	NextCursor() string
+}
+
+// Offset paginates by page number, starting at 1
+type Offset struct {
+	page  int
+	limit int
+	total int64
+}
+
+func NewOffset(page int, limit int, total int64) *Offset {
+	if page < 1 {
+		page = 1
+	}
+	return &Offset{page: page, limit: limit, total: total}
+}
+
+func (o *Offset) Page() int {
+	return o.page
+}
+
+func (o *Offset) Limit() int {
+	return o.limit
+}
+
+func (o *Offset) Total() int64 {
+	return o.total
+}
+
+// NextCursor is always empty, offset pages are walked by Page
+func (o *Offset) NextCursor() string {
+	return ""
+}
+
+// Offset is the number of rows to skip, e.g db.Offset(p.Offset()).Limit(p.Limit())
+func (o *Offset) Offset() int {
+	return (o.page - 1) * o.limit
+}
+
+// Cursor paginates by an opaque cursor pointing to the next page
+type Cursor struct {
+	limit int
+	total int64
+	next  string
+}
+
+func NewCursor(limit int, total int64, next string) *Cursor {
+	return &Cursor{limit: limit, total: total, next: next}
+}
+
+// Page is always 0, cursor pages aren't numbered
+func (c *Cursor) Page() int {
+	return 0
+}
+
+func (c *Cursor) Limit() int {
+	return c.limit
+}
+
+func (c *Cursor) Total() int64 {
+	return c.total
+}
+
+// NextCursor is empty on the last page
+func (c *Cursor) NextCursor() string {
+	return c.next
+}
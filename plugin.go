@@ -3,28 +3,26 @@ package main
 import (
 	"errors"
 	"fmt"
-	"go/ast"
-	"go/parser"
 	"go/token"
+	"go/types"
+	"io"
 	"io/ioutil"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	version "github.com/crowdeco/protoc-gen-bima/internal"
 	gorm "github.com/crowdeco/protoc-gen-bima/options"
 	"github.com/iancoleman/strcase"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	// "google.golang.org/protobuf/types/descriptorpb"
 )
 
-var GenerateVersionMarkers = true
-var ptypesImport = "github.com/golang/protobuf/ptypes"
-
-type structFields = map[string]string
-
 var basicTypes = map[string]struct{}{
 	"bool": {},
 	"int":  {},
@@ -51,32 +49,103 @@ var wellKnownTypes = map[string]string{
 	"BytesValue":  "[]byte", // * this was commented in origin
 }
 
-var sqlTypes = map[string]string{
-	"sql.NullString":  "String",
-	"sql.NullInt64":   "Int64",
-	"sql.NullInt32":   "Int32",
-	"sql.NullFloat64": "Float64",
-	"sql.NullBool":    "Bool",
-	"sql.NullTime":    "Time",
+type structType struct {
+	native func(t types.Type) bool // * go type the message converts from/to without marshalling
+	as     string
+	new    string
+}
+
+var structTypes = map[protoreflect.FullName]structType{
+	"google.protobuf.Struct":    {native: isInterfaceMap, as: "AsMap", new: "NewStruct"},
+	"google.protobuf.Value":     {native: isEmptyInterface, as: "AsInterface", new: "NewValue"},
+	"google.protobuf.ListValue": {native: isInterfaceSlice, as: "AsSlice", new: "NewList"},
 }
 
-var statusOk = []string{"StatusOK", "StatusCreated", "StatusNoContent"}
-var statusNotOk = []string{"StatusBadRequest", "StatusNotFound", "StatusInternalServerError"}
+// * outputs picked by the emit parameter, conversions and responses are generated by default
+const (
+	emitConversions = "conversions"
+	emitResponses   = "responses"
+	emitRepository  = "repository" // * opt-in, needs conversions
+	emitServer      = "server"     // * opt-in, needs responses and repository
+	emitMigrations  = "migrations" // * opt-in
+	emitModels      = "models"     // * opt-in, only models which don't exist yet
+)
+
+var emitKinds = []string{emitConversions, emitResponses, emitRepository, emitServer, emitMigrations, emitModels}
+
+type enumMap struct {
+	enum     *protogen.Enum
+	enumCase gorm.GormFieldOptions_EnumCase
+}
+
+func (e enumMap) name(v *protogen.EnumValue) string {
+	name := string(v.Desc.Name())
+	if e.enumCase == gorm.GormFieldOptions_SNAKE {
+		return strcase.ToSnake(name)
+	}
+	return strings.ToLower(name)
+}
 
 type BimaPlugin struct {
 	*protogen.Plugin
 	files             map[string]*fileInfo
 	modelExports      map[string]bool
 	modelTypes        map[string]structFields
+	modelColumns      map[string]structFields // * like modelTypes, with bima:"-" fields kept
+	packages          map[protogen.GoImportPath]*packages.Package
+	fset              *token.FileSet
+	enumMaps          map[string]enumMap
 	packageName       string
 	loggerHasDeclared bool
+	diagnostics       map[diagnostic]bool
+	grpcStatusUsed    bool                                      // * the current file has gRPC error helpers
+	grpcStatusDecls   map[protogen.GoImportPath]bool            // * packages bimaStatusError was declared in
+	enumMapDecls      map[protogen.GoImportPath]map[string]bool // * enum maps declared per package
+	schemas           map[string]tableSchema                    // * tables as left by the last migration
+	migrationVersion  int
+	failures          []string  // * error diagnostics, the generation fails with all of them
+	stderr            io.Writer // * where diagnostics are written, os.Stderr when nil
+
+	// * plugin parameters
+	versionMarkers  bool
+	strict          bool
+	diagnosticsJSON bool
+	modelRoot       string
+	responseSuffix  string
+	statuses        []status
+	emit            map[string]bool
+	grpcErrors      bool
+	dialect         string
+	migrationsDir   string
+	modelBase       protogen.GoIdent // * embedded by generated models, none when GoName is empty
+	module          string           // * protogen's module parameter, read from the request as protogen keeps it
 }
 
 func (p BimaPlugin) Generate(plugin *protogen.Plugin) {
 	p.init(plugin)
 	p.findMarkedFiles()
-	for _, f := range p.files {
-		p.generateFile(f)
+	// * sorted, so declarations shared by a package always land in the same file
+	names := make([]string, 0, len(p.files))
+	for name := range p.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// * models first, the other outputs of any file may need them
+	if p.emit[emitModels] {
+		for _, name := range names {
+			for _, m := range p.files[name].Messages {
+				if mi, ok := getModelIdent(m.Desc); ok {
+					p.genModel(p.files[name].File, m, mi)
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		p.generateFile(p.files[name])
+	}
+	// * protogen keeps the first error only
+	if len(p.failures) > 0 {
+		p.Error(errors.New(strings.Join(p.failures, "\n")))
 	}
 }
 
@@ -91,10 +160,71 @@ func (p *BimaPlugin) init(plugin *protogen.Plugin) {
 	if p.modelTypes == nil {
 		p.modelTypes = make(map[string]structFields)
 	}
-	p.packageName = getPackageName()
+	if p.modelColumns == nil {
+		p.modelColumns = make(map[string]structFields)
+	}
+	if p.packages == nil {
+		p.packages = make(map[protogen.GoImportPath]*packages.Package)
+	}
+	if p.fset == nil {
+		p.fset = token.NewFileSet()
+	}
+	if p.diagnostics == nil {
+		p.diagnostics = make(map[diagnostic]bool)
+	}
+	if p.grpcStatusDecls == nil {
+		p.grpcStatusDecls = make(map[protogen.GoImportPath]bool)
+	}
+	if p.enumMapDecls == nil {
+		p.enumMapDecls = make(map[protogen.GoImportPath]map[string]bool)
+	}
+	if p.responseSuffix == "" {
+		p.responseSuffix = "Response"
+	}
+	if p.statuses == nil {
+		for _, name := range defaultStatuses {
+			s, _ := newStatus(name)
+			p.statuses = append(p.statuses, s)
+		}
+	}
+	if p.dialect == "" {
+		p.dialect = dialectPostgres
+	}
+	if p.migrationsDir == "" {
+		p.migrationsDir = "migrations"
+	}
+	if len(p.emit) == 0 {
+		p.emit = map[string]bool{emitConversions: true, emitResponses: true}
+	}
+	if p.emit[emitRepository] && !p.emit[emitConversions] {
+		p.diagnose(severityError, nil, token.NoPos, "emit=%s needs emit=%s", emitRepository, emitConversions)
+	}
+	for _, kind := range []string{emitResponses, emitRepository} {
+		if p.emit[emitServer] && !p.emit[kind] {
+			p.diagnose(severityError, nil, token.NoPos, "emit=%s needs emit=%s", emitServer, kind)
+		}
+	}
+	p.packageName = getPackageName(p.modelRoot)
 	if p.packageName == "" {
-		println("Warning: go.mod not found")
+		p.diagnose(severityWarning, nil, token.NoPos, "go.mod not found")
+	}
+	p.module = getModuleParam(plugin.Request.GetParameter())
+	// * with module= every output must be under the module, migrations are written to model_root's
+	// module they are read from
+	if p.emit[emitMigrations] && p.module != "" && p.packageName != p.module && !strings.HasPrefix(p.packageName, p.module+"/") {
+		p.diagnose(severityError, nil, token.NoPos, "emit=%s with module=%s needs model_root in that module, model_root is in module %q",
+			emitMigrations, p.module, p.packageName)
+	}
+}
+
+// getModuleParam returns the value of the module parameter, e.g module=github.com/crowdeco/skeleton
+func getModuleParam(parameter string) string {
+	for _, param := range strings.Split(parameter, ",") {
+		if strings.HasPrefix(param, "module=") {
+			return strings.TrimPrefix(param, "module=")
+		}
 	}
+	return ""
 }
 
 func (p *BimaPlugin) findMarkedFiles() {
@@ -105,19 +235,18 @@ func (p *BimaPlugin) findMarkedFiles() {
 		for _, m := range f.Messages {
 			p.inspect(f, m.Desc)
 		}
+		if p.emit[emitServer] && len(f.Services) > 0 {
+			if _, exists := p.files[*f.Proto.Name]; !exists {
+				p.files[*f.Proto.Name] = newFileInfo(f)
+			}
+		}
 	}
 }
 
 func (p *BimaPlugin) inspect(f *protogen.File, m protoreflect.MessageDescriptor) {
 	if opts := getMessageOptions(m); opts != nil {
 		if _, exists := p.files[*f.Proto.Name]; !exists {
-			hasTimestamp := false
-			for _, dep := range f.Proto.Dependency {
-				if dep == "google/protobuf/timestamp.proto" {
-					hasTimestamp = true
-				}
-			}
-			p.files[*f.Proto.Name] = newFileInfo(f, hasTimestamp)
+			p.files[*f.Proto.Name] = newFileInfo(f)
 		}
 	}
 }
@@ -147,25 +276,50 @@ func (p *BimaPlugin) generateFile(f *fileInfo) {
 	// 	p.loggerHasDeclared = true
 	// }
 
-	reResponse := regexp.MustCompile(`Response$`)
+	p.enumMaps = make(map[string]enumMap)
+	p.grpcStatusUsed = false
+
+	reResponse := regexp.MustCompile(regexp.QuoteMeta(p.responseSuffix) + `$`)
 	for _, m := range file.Messages {
-		if mi, ok := getModelIdent(m.Desc); ok {
-			p.genWeakTimestamp(g, f)
+		if mi, ok := getModelIdent(m.Desc); ok && p.emit[emitConversions] {
 			p.genModelExport(g, mi)
 			p.genBindFunc(g, m, mi)
 			p.genBundleFunc(g, m, mi)
+			if p.strict {
+				p.checkModelFields(m, mi)
+			}
+			if p.emit[emitRepository] {
+				p.genRepository(g, m, mi)
+			}
+		}
+		if p.emit[emitResponses] {
+			p.genResponseStatusMethod(g, m, file.Messages)
+			if reResponse.MatchString(m.GoIdent.GoName) {
+				p.genResponseStatusFunc(g, m)
+			}
 		}
-		p.genResponseStatusMethod(g, m, file.Messages)
-		if reResponse.MatchString(m.GoIdent.GoName) {
-			p.genResponseStatusFunc(g, m)
+	}
+
+	if p.emit[emitMigrations] {
+		p.genMigrations(file)
+	}
+	if p.emit[emitServer] {
+		for _, s := range file.Services {
+			p.genServer(g, s)
 		}
 	}
+
+	p.genEnumMaps(g, file.GoImportPath)
+	if p.grpcStatusUsed && !p.grpcStatusDecls[file.GoImportPath] {
+		p.genGRPCStatusError(g)
+		p.grpcStatusDecls[file.GoImportPath] = true
+	}
 }
 
 func (p *BimaPlugin) genGeneratedHeader(g *protogen.GeneratedFile, f *protogen.File) {
 	g.P("// Code generated by protoc-gen-bima. DO NOT EDIT.")
 
-	if GenerateVersionMarkers {
+	if p.versionMarkers {
 		g.P("// versions:")
 		protocGenBimaVersion := version.String()
 		protocVersion := "(unknown)"
@@ -197,13 +351,6 @@ func (p *BimaPlugin) genImport(g *protogen.GeneratedFile, file *protogen.File, i
 	g.P()
 }
 
-func (p *BimaPlugin) genWeakTimestamp(g *protogen.GeneratedFile, f *fileInfo) {
-	if f.hasTimestamp {
-		g.P("type _ timestamp.Timestamp")
-		g.P()
-	}
-}
-
 func (p *BimaPlugin) genModelExport(g *protogen.GeneratedFile, model protogen.GoIdent) {
 	if !p.modelExports[model.GoName] {
 		g.P("type ", model.GoName, "Model = ", model) // * e.g TodoModel
@@ -213,12 +360,11 @@ func (p *BimaPlugin) genModelExport(g *protogen.GeneratedFile, model protogen.Go
 }
 
 func (p *BimaPlugin) genBindFunc(g *protogen.GeneratedFile, m *protogen.Message, model protogen.GoIdent) {
-	if p.walkModelFields(model) {
-		g.P("func (x *", m.GoIdent, ") Bind(v *", model, ") {")
+	if p.walkModelFields(m.Desc, model) {
+		g.P("func (x *", m.GoIdent, ") Bind(v *", model, ") error {")
 		g.P("to, from := v, x")
-		for _, f := range m.Fields {
-			p.genFieldConversion(g, m, f, model, false)
-		}
+		p.genFieldConversions(g, m, model, false)
+		g.P("return nil")
 		g.P("}")
 		g.P()
 		p.genToModelFunc(g, m, model)
@@ -226,52 +372,63 @@ func (p *BimaPlugin) genBindFunc(g *protogen.GeneratedFile, m *protogen.Message,
 }
 
 func (p *BimaPlugin) genToModelFunc(g *protogen.GeneratedFile, m *protogen.Message, model protogen.GoIdent) {
-	g.P("func (x *", m.GoIdent, ") ToModel() ", model, " {")
+	g.P("func (x *", m.GoIdent, ") ToModel() (", model, ", error) {")
 	g.P("v := ", model, "{}")
-	g.P("x.Bind(&v)")
-	g.P("return v")
+	g.P("err := x.Bind(&v)")
+	g.P("return v, err")
 	g.P("}")
 	g.P()
 }
 
 func (p *BimaPlugin) genBundleFunc(g *protogen.GeneratedFile, m *protogen.Message, model protogen.GoIdent) {
-	if p.walkModelFields(model) {
-		g.P("func (x *", m.GoIdent, ") Bundle(v *", model, ") {")
+	if p.walkModelFields(m.Desc, model) {
+		g.P("func (x *", m.GoIdent, ") Bundle(v *", model, ") error {")
 		g.P("to, from := x, v")
-		for _, f := range m.Fields {
-			p.genFieldConversion(g, m, f, model, true)
-		}
+		p.genFieldConversions(g, m, model, true)
+		g.P("return nil")
 		g.P("}")
 		g.P()
 	}
 }
 
 func (p *BimaPlugin) genResponseStatusMethod(g *protogen.GeneratedFile, m *protogen.Message, ms []*protogen.Message) {
-	reResponse := regexp.MustCompile(`Response$`)
+	reResponse := regexp.MustCompile(regexp.QuoteMeta(p.responseSuffix) + `$`)
 	for _, msg := range ms {
 		if reResponse.MatchString(msg.GoIdent.GoName) {
 			for _, field := range msg.Fields {
 				if field.Desc.Name() == "data" && field.Message == m && !field.Desc.IsList() {
-					g.QualifiedGoIdent(protogen.GoIdent{
-						GoImportPath: "net/http",
-					})
-					for _, status := range statusOk {
-						g.P("func (x *", m.GoIdent, ") ", msg.GoIdent, status, "() (*", msg.GoIdent, ", error) {")
+					statuses := p.responseStatuses(msg)
+					for _, status := range statuses {
+						if !status.ok() {
+							continue
+						}
+						g.P("func (x *", m.GoIdent, ") ", msg.GoIdent, status.name, "() (*", msg.GoIdent, ", error) {")
 						g.P("return &", msg.GoIdent, "{")
-						g.P("Code: http.", status, ",")
-						if status != "StatusNoContent" {
+						g.P("Code: ", status.codeValue(g), ",")
+						if status.hasData() {
 							g.P("Data: x,")
 						}
 						g.P("}, nil")
 						g.P("}")
 						g.P()
 					}
-					for _, status := range statusNotOk {
-						g.P("func (x *", m.GoIdent, ") ", msg.GoIdent, status, "(err error) (*", msg.GoIdent, ", error) {")
+					for _, status := range statuses {
+						if status.ok() {
+							continue
+						}
+						g.P("func (x *", m.GoIdent, ") ", msg.GoIdent, status.name, "(err error) (*", msg.GoIdent, ", error) {")
+						if p.grpcErrors {
+							p.genGRPCStatusReturn(g, status)
+							g.P("}")
+							g.P()
+							continue
+						}
 						g.P("return &", msg.GoIdent, "{")
-						g.P("Code: http.", status, ",")
+						g.P("Code: ", status.codeValue(g), ",")
 						g.P("Data: x,")
-						g.P("Message: err.Error(),")
+						if hasField(msg, "message") {
+							g.P("Message: err.Error(),")
+						}
 						g.P("}, nil")
 						g.P("}")
 						g.P()
@@ -283,39 +440,65 @@ func (p *BimaPlugin) genResponseStatusMethod(g *protogen.GeneratedFile, m *proto
 }
 
 func (p *BimaPlugin) genResponseStatusFunc(g *protogen.GeneratedFile, m *protogen.Message) {
-	rePaginatedResponse := regexp.MustCompile(`PaginatedResponse$`)
 	for _, field := range m.Fields {
-		// TODO: support paginate too
 		if field.Desc.Name() == "data" {
 			if field.Message != nil {
-				p := "*"
+				ptr := "*"
 				if field.Desc.IsList() {
-					p = "[]*"
+					ptr = "[]*"
 				}
-				g.QualifiedGoIdent(protogen.GoIdent{
-					GoImportPath: "net/http",
-				})
-				for _, status := range statusOk {
-					if status == "StatusNoContent" {
-						g.P("func ", m.GoIdent, status, "() (*", m.GoIdent, ", error) {")
+				var meta *protogen.Field
+				if field.Desc.IsList() {
+					meta = getPaginationField(m)
+				}
+				statuses := p.responseStatuses(m)
+				for _, status := range statuses {
+					if !status.ok() {
+						continue
+					}
+					if !status.hasData() {
+						g.P("func ", m.GoIdent, status.name, "() (*", m.GoIdent, ", error) {")
 					} else {
-						g.P("func ", m.GoIdent, status, "(d ", p, field.Message.GoIdent, ") (*", m.GoIdent, ", error) {")
+						g.P("func ", m.GoIdent, status.name, "(d ", ptr, field.Message.GoIdent, ") (*", m.GoIdent, ", error) {")
 					}
 					g.P("return &", m.GoIdent, "{")
-					g.P("Code: http.", status, ",")
-					if status != "StatusNoContent" {
+					g.P("Code: ", status.codeValue(g), ",")
+					if status.hasData() {
 						g.P("Data: d,")
 					}
 					g.P("}, nil")
 					g.P("}")
 					g.P()
+					if meta == nil || !status.hasData() {
+						continue
+					}
+					// * pg is satisfied by paginator.Offset and paginator.Cursor without importing them
+					g.P("func ", m.GoIdent, status.name, "WithPaginator(d ", ptr, field.Message.GoIdent,
+						", pg interface{ Page() int; Limit() int; Total() int64; NextCursor() string }) (*", m.GoIdent, ", error) {")
+					g.P("r, err := ", m.GoIdent, status.name, "(d)")
+					g.P("if err != nil {")
+					g.P("return nil, err")
+					g.P("}")
+					p.genPaginationMeta(g, meta)
+					g.P("return r, nil")
+					g.P("}")
+					g.P()
 				}
-				for _, status := range statusNotOk {
-					g.P("func ", m.GoIdent, status, "(d ", p, field.Message.GoIdent, ", err error) (*", m.GoIdent, ", error) {")
+				for _, status := range statuses {
+					if status.ok() {
+						continue
+					}
+					g.P("func ", m.GoIdent, status.name, "(d ", ptr, field.Message.GoIdent, ", err error) (*", m.GoIdent, ", error) {")
+					if p.grpcErrors {
+						p.genGRPCStatusReturn(g, status)
+						g.P("}")
+						g.P()
+						continue
+					}
 					g.P("return &", m.GoIdent, "{")
-					g.P("Code: http.", status, ",")
+					g.P("Code: ", status.codeValue(g), ",")
 					g.P("Data: d,")
-					if !rePaginatedResponse.MatchString(m.GoIdent.GoName) {
+					if hasField(m, "message") {
 						g.P("Message: err.Error(),")
 					}
 					g.P("}, nil")
@@ -327,281 +510,873 @@ func (p *BimaPlugin) genResponseStatusFunc(g *protogen.GeneratedFile, m *protoge
 	}
 }
 
-func (p *BimaPlugin) walkModelFields(model protogen.GoIdent) bool {
-	// * assume filename is snake case
-	filename := string(model.GoImportPath) + "/" + strcase.ToSnake(model.GoName) + ".go"
-	origin := filename
-	if _, ok := p.modelTypes[model.GoName]; ok {
-		return true
+// genPaginationMeta fills the response's meta or pagination field from pg, the meta's
+// fields are matched by name: page, limit (or per_page, page_size), total and next_cursor
+func (p *BimaPlugin) genPaginationMeta(g *protogen.GeneratedFile, meta *protogen.Field) {
+	g.P("if pg != nil {")
+	g.P("r.", meta.GoName, " = &", meta.Message.GoIdent, "{")
+	for _, f := range meta.Message.Fields {
+		var value string
+		switch f.Desc.Name() {
+		case "page":
+			value = "pg.Page()"
+		case "limit", "per_page", "page_size":
+			value = "pg.Limit()"
+		case "total":
+			value = "pg.Total()"
+		case "next_cursor":
+			value = "pg.NextCursor()"
+		default:
+			continue
+		}
+		supported := isIntegerKind(f.Desc.Kind())
+		if f.Desc.Name() == "next_cursor" {
+			supported = f.Desc.Kind() == protoreflect.StringKind
+		}
+		if !supported || f.Desc.IsList() || f.Desc.HasPresence() {
+			p.diagnose(severityWarning, f.Desc, token.NoPos, "pagination field %s has unsupported type, skipping", f.Desc.Name())
+			continue
+		}
+		if f.Desc.Kind() == protoreflect.StringKind {
+			g.P(f.GoName, ": ", value, ",")
+			continue
+		}
+		goType, _ := fieldGoType(g, f)
+		g.P(f.GoName, ": ", goType, "(", value, "),")
 	}
+	g.P("}")
+	g.P("}")
+}
+
+// genGRPCStatusReturn returns the error of an error helper as a gRPC status, the response is dropped
+func (p *BimaPlugin) genGRPCStatusReturn(g *protogen.GeneratedFile, status status) {
+	g.P("return nil, bimaStatusError(", status.grpcCode(g), ", err)")
+	p.grpcStatusUsed = true
+}
 
-	fset := token.NewFileSet()
+// genGRPCStatusError declares bimaStatusError once per package, it attaches a BadRequest to
+// InvalidArgument statuses when the error, or the errors it gathers through AllErrors() []error,
+// tells the field it's about through Field() and Reason(), e.g protoc-gen-validate's errors
+func (p *BimaPlugin) genGRPCStatusError(g *protogen.GeneratedFile) {
+	codes := g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       "Code",
+		GoImportPath: "google.golang.org/grpc/codes",
+	})
+	errdetails := protogen.GoImportPath("google.golang.org/genproto/googleapis/rpc/errdetails")
 
-	var astFile *ast.File
-	var err error
+	g.P("func bimaStatusError(code ", codes, ", err error) error {")
+	g.P("s := ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "New", GoImportPath: "google.golang.org/grpc/status"}), "(code, err.Error())")
+	g.P("if code != ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "InvalidArgument", GoImportPath: "google.golang.org/grpc/codes"}), " {")
+	g.P("return s.Err()")
+	g.P("}")
+	g.P("errs := []error{err}")
+	g.P("if m, ok := err.(interface{ AllErrors() []error }); ok {")
+	g.P("errs = m.AllErrors()")
+	g.P("}")
+	g.P("var violations []*", errdetails.Ident("BadRequest_FieldViolation"))
+	g.P("for _, e := range errs {")
+	g.P("var v interface {")
+	g.P("Field() string")
+	g.P("Reason() string")
+	g.P("}")
+	g.P("if ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "As", GoImportPath: "errors"}), "(e, &v) {")
+	g.P("violations = append(violations, &", errdetails.Ident("BadRequest_FieldViolation"), "{")
+	g.P("Field: v.Field(),")
+	g.P("Description: v.Reason(),")
+	g.P("})")
+	g.P("}")
+	g.P("}")
+	g.P("if len(violations) == 0 {")
+	g.P("return s.Err()")
+	g.P("}")
+	g.P("if d, derr := s.WithDetails(&", errdetails.Ident("BadRequest"), "{FieldViolations: violations}); derr == nil {")
+	g.P("return d.Err()")
+	g.P("}")
+	g.P("return s.Err()")
+	g.P("}")
+	g.P()
+}
 
-	for astFile == nil && len(strings.Split(filename, "/")) > 1 {
-		if astFile, err = parser.ParseFile(fset, filename, nil, parser.ParseComments); astFile != nil {
-			break
+// genFieldConversions converts every field of the message, to and from are left unused when each
+// field is skipped or in a oneof, whose conversion shadows from (Bind) or to (Bundle)
+func (p *BimaPlugin) genFieldConversions(g *protogen.GeneratedFile, m *protogen.Message, model protogen.GoIdent, toX bool) {
+	fields, oneofs := false, false
+	for _, f := range m.Fields {
+		if isOneofField(f) {
+			if f == f.Oneof.Fields[0] && p.genOneofConversion(g, m, f.Oneof, model, toX) {
+				oneofs = true
+			}
+			continue
 		}
-		if i := strings.Index(filename, "/"); i >= 0 {
-			filename = filename[i+1:]
-		} else {
-			break
+		if p.genFieldConversion(g, m, f, model, toX) {
+			fields = true
 		}
 	}
+	switch {
+	case fields:
+	case !oneofs:
+		g.P("_, _ = to, from")
+	case toX:
+		g.P("_ = to")
+	default:
+		g.P("_ = from")
+	}
+}
+
+// genFieldConversion converts a field, false when it's skipped
+func (p *BimaPlugin) genFieldConversion(g *protogen.GeneratedFile, m *protogen.Message, field *protogen.Field, model protogen.GoIdent, toX bool) bool {
+	fieldType, pointer := fieldGoType(g, field)
+	// * oneof wrappers hold the value itself
+	if pointer && !isOneofField(field) {
+		fieldType = "*" + fieldType
+	}
 
-	if err != nil {
-		p.Error(err)
+	structFields, exists := p.modelTypes[model.GoName]
+	if !exists {
+		p.diagnose(severityError, field.Desc, token.NoPos, "model %s wasn't loaded, please ask the author about this error", model.GoName)
 		return false
 	}
+	modelField, ok := getModelField(structFields, field, toX)
+	if !ok {
+		if name := getFieldOptions(field.Desc).GetModelField(); name != "" && !isSkipped(field, toX) {
+			// * a misspelled mapping is worth a warning, unlike a field the model just doesn't have
+			p.skipField(field, model, nil, fmt.Sprintf("model %s has no field %s set by model_field", model.GoName, name), true)
+		} else if !isSkipped(field, toX) {
+			p.skipField(field, model, nil, "the model has no such field", false)
+		}
+		return false
+	}
+	modelName := modelField.Name()
 
-	for _, decl := range astFile.Decls {
-		switch decl := decl.(type) {
-		case *ast.GenDecl:
-			for _, spec := range decl.Specs {
-				switch spec := spec.(type) {
-				case *ast.TypeSpec:
-					switch st := spec.Type.(type) {
-					case *ast.StructType:
-						if spec.Name.Name == model.GoName {
-							sf := structFields{}
-							// * exclude embedded struct e.g bima.Model
-
-							for _, field := range st.Fields.List {
-								var fieldName string
-								// * a,b type ; a type ; not configs.Base
-								for _, name := range field.Names {
-									fieldName = name.Name
-									switch ft := field.Type.(type) {
-									case *ast.Ident:
-										sf[fieldName] = ft.Name
-									case *ast.StarExpr:
-										switch sft := ft.X.(type) {
-										case *ast.Ident:
-											sf[fieldName] = "*" + sft.Name
-										case *ast.SelectorExpr:
-											sf[fieldName] = "*" + sft.X.(*ast.Ident).Name + "." + sft.Sel.Name
-										}
-									case *ast.SelectorExpr:
-										sf[fieldName] = ft.X.(*ast.Ident).Name + "." + ft.Sel.Name
-										// case *ast.ArrayType:
-										// 	switch fte := ft.Elt.(type) {
-										// 	case *ast.Ident:
-										// 		sf[fieldName] = "[]" + fte.Name
-										// 	case *ast.StarExpr:
-										// 		sf[fieldName] = "[]*" + fte.X.(*ast.Ident).Name
-										// 	}
-									}
-								}
-							}
-							p.modelTypes[model.GoName] = sf
-							return true
-						}
-					}
-				}
+	// * Bind goes from proto to model, Bundle the other way around
+	toName, fromName := modelName, field.GoName
+	if toX {
+		toName, fromName = field.GoName, modelName
+	}
+
+	switch {
+	case field.Desc.IsList():
+		if field.Desc.Message() == nil {
+			p.genListConversion(g, field, fieldType, modelField, model, toName, fromName, toX)
+		} else if nested, ok := getModelIdent(field.Message.Desc); ok {
+			p.genNestedListConversion(g, field, modelField, model, nested, toName, fromName, toX)
+		} else {
+			p.skipField(field, model, modelField, fmt.Sprintf("message %s has no model", field.Message.Desc.FullName()), false)
+			return false
+		}
+	case field.Desc.IsMap():
+		p.genMapConversion(g, field, fieldType, modelField, model, toName, fromName, toX)
+	case field.Desc.Message() != nil:
+		if valueType, ok := wellKnownTypes[field.Message.GoIdent.GoName]; ok {
+			p.genWrapperConversion(g, field, fieldType[1:], valueType, modelField, model, modelName, toName, fromName, toX)
+		} else if field.Message.Desc.FullName() == "google.protobuf.Timestamp" {
+			p.genTimestampConversion(g, field, modelField, toName, fromName, toX)
+		} else if field.Message.Desc.FullName() == "google.protobuf.Duration" {
+			p.genDurationConversion(g, field, modelField, toName, fromName, toX)
+		} else if st, ok := structTypes[field.Message.Desc.FullName()]; ok {
+			p.genStructConversion(g, field, st, modelField, model, toName, fromName, toX)
+		} else if nested, ok := getModelIdent(field.Message.Desc); ok {
+			p.genNestedConversion(g, field, modelField, model, nested, toName, fromName, toX)
+		} else {
+			p.skipField(field, model, modelField, fmt.Sprintf("message %s has no model", field.Message.Desc.FullName()), false)
+			return false
+		}
+	default:
+		p.genScalarConversion(g, field, fieldType, modelField, model, modelName, toName, fromName, toX)
+	}
+	return true
+}
+
+func (p *BimaPlugin) genScalarConversion(g *protogen.GeneratedFile, field *protogen.Field, fieldType string, modelField *modelField, model protogen.GoIdent, modelName string, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	coreType, pointer := derefType(t)
+	if field.Desc.Kind() == protoreflect.EnumKind && isBasicKind(coreType, types.IsString) {
+		if pointer {
+			p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported", typeName(t))
+			return
+		}
+		p.genEnumConversion(g, field, coreType, toName, fromName, toX)
+		return
+	}
+	if value, ok := nullValue(coreType); ok {
+		p.skipField(field, model, modelField, fmt.Sprintf("Please change field %s on model %s to %s type, it wouldn't benefit anything", modelName, model.GoName, typeName(value.Type())), true)
+		// TODO: should we generate ?
+		return
+	}
+
+	// * proto3 optional fields are pointers too
+	pbType := strings.TrimPrefix(fieldType, "*")
+	pbPointer := pbType != fieldType
+	same := typePath(coreType) == pbType
+	if !same && !isConvertible(pbType, coreType) && !(field.Desc.Kind() == protoreflect.EnumKind && isBasicKind(coreType, types.IsInteger)) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for field %s on model %s", typeName(t), field.GoName, model.GoName)
+		return
+	}
+
+	convert := func(r string) string {
+		if same {
+			return r
+		}
+		if toX {
+			return pbType + "(" + r + ")"
+		}
+		return goTypeString(g, coreType) + "(" + r + ")"
+	}
+	toPointer, fromPointer := pointer, pbPointer
+	if toX {
+		toPointer, fromPointer = pbPointer, pointer
+	}
+
+	switch {
+	case toPointer && fromPointer && same:
+		g.P("to.", toName, " = from.", fromName)
+	case toPointer && fromPointer:
+		g.P("if from.", fromName, " != nil {")
+		g.P("v := ", convert("*from."+fromName))
+		g.P("to.", toName, " = &v")
+		g.P("} else {")
+		g.P("to.", toName, " = nil")
+		g.P("}")
+	case toPointer && same:
+		g.P("to.", toName, " = &from.", fromName)
+	case toPointer:
+		// * in a block of its own, every field names its temporary v
+		g.P("{")
+		g.P("v := ", convert("from."+fromName))
+		g.P("to.", toName, " = &v")
+		g.P("}")
+	case fromPointer:
+		g.P("if from.", fromName, " != nil {")
+		g.P("to.", toName, " = ", convert("*from."+fromName))
+		g.P("}")
+	default:
+		g.P("to.", toName, " = ", convert("from."+fromName))
+	}
+}
+
+// genWrapperConversion converts wrapperspb messages from/to pointers or sql.Null* like types
+func (p *BimaPlugin) genWrapperConversion(g *protogen.GeneratedFile, field *protogen.Field, wrapperType string, valueType string, modelField *modelField, model protogen.GoIdent, modelName string, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	coreType, pointer := derefType(t)
+	value, isNull := nullValue(coreType)
+	if isNull && pointer {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported", typeName(t))
+		return
+	}
+	if !isNull && !pointer {
+		p.skipField(field, model, modelField, fmt.Sprintf("Please change protobuf type message's field regarding type of %s on model %s", modelName, model.GoName), true)
+		// TODO: should we generate ?
+		return
+	}
+
+	valueField := coreType
+	if isNull {
+		valueField = value.Type()
+	}
+	same := typePath(valueField) == valueType
+	if !same && !isConvertible(valueType, valueField) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for %s on model %s", typeName(t), field.Message.GoIdent.GoName, model.GoName)
+		return
+	}
+
+	if toX {
+		r := "from." + fromName
+		if isNull {
+			g.P("if from.", fromName, ".Valid {")
+			r += "." + value.Name()
+		} else {
+			g.P("if from.", fromName, " != nil {")
+			r = "*" + r
+		}
+		if !same {
+			r = valueType + "(" + r + ")"
+		}
+		g.P("to.", toName, " = &", wrapperType, "{Value: ", r, "}")
+		g.P("}")
+	} else {
+		g.P("if from.", fromName, " != nil {")
+		r := "from." + fromName + ".Value"
+		if !same {
+			r = goTypeString(g, valueField) + "(" + r + ")"
+		}
+		switch {
+		case isNull:
+			g.P("to.", toName, " = ", goTypeString(g, coreType), "{", value.Name(), ": ", r, ", Valid: true}")
+		case same:
+			g.P("to.", toName, " = &", r)
+		default:
+			g.P("v := ", r)
+			g.P("to.", toName, " = &v")
+		}
+		g.P("}")
+	}
+}
+
+func (p *BimaPlugin) genTimestampConversion(g *protogen.GeneratedFile, field *protogen.Field, modelField *modelField, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	coreType, pointer := derefType(t)
+	value, isNull := nullValue(coreType)
+	if isNull {
+		if typePath(value.Type()) != "time.Time" {
+			p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for Timestamp", typeName(t))
+			return
+		}
+		if pointer {
+			p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported", typeName(t))
+			return
+		}
+	} else if typePath(coreType) != "time.Time" {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for Timestamp", typeName(t))
+		return
+	}
+
+	if toX {
+		newTimestamp := protogen.GoIdent{
+			GoName:       "New",
+			GoImportPath: field.Message.GoIdent.GoImportPath,
+		}
+		if isNull {
+			g.P("if from.", fromName, ".Valid {")
+			g.P("to.", toName, " = ", newTimestamp, "(from.", fromName, ".", value.Name(), ")")
+			g.P("}")
+		} else if pointer {
+			g.P("if from.", fromName, " != nil {")
+			g.P("to.", toName, " = ", newTimestamp, "(*from.", fromName, ")")
+			g.P("}")
+		} else {
+			g.P("to.", toName, " = ", newTimestamp, "(from.", fromName, ")")
+		}
+	} else {
+		g.P("if from.", fromName, " != nil {")
+		g.P("if from.", fromName, ".IsValid() {")
+		if isNull {
+			g.P("to.", toName, " = ", goTypeString(g, coreType), "{", value.Name(), ": from.", fromName, ".AsTime(), Valid: true}")
+		} else if pointer {
+			g.P("t := from.", fromName, ".AsTime()")
+			g.P("to.", toName, " = &t")
+		} else {
+			g.P("to.", toName, " = from.", fromName, ".AsTime()")
+		}
+		g.P("}")
+		g.P("}")
+	}
+}
+
+// genOneofConversion converts each member of a oneof from/to its own model field,
+// the member's conversion runs with from (Bind) or to (Bundle) shadowed by the oneof wrapper
+func (p *BimaPlugin) genOneofConversion(g *protogen.GeneratedFile, m *protogen.Message, oneof *protogen.Oneof, model protogen.GoIdent, toX bool) bool {
+	structFields := p.modelTypes[model.GoName]
+
+	var fields []*protogen.Field
+	for _, f := range oneof.Fields {
+		if modelField, ok := getModelField(structFields, f, toX); ok {
+			// * Bundle picks the member by comparing the model field to its zero value
+			if toX && !hasSetCondition(modelField.Type()) && !types.Comparable(modelField.Type()) {
+				p.diagnose(severityError, f.Desc, modelField.Pos(), "type %s of field %s on model %s can't tell whether oneof %s is set, use a pointer",
+					typeName(modelField.Type()), modelField.Name(), model.GoName, oneof.Desc.Name())
+				continue
 			}
+			fields = append(fields, f)
+		} else if !isSkipped(f, toX) {
+			p.skipField(f, model, nil, "the model has no such field", false)
 		}
 	}
+	if len(fields) == 0 {
+		return false
+	}
 
-	println(fmt.Sprintf("couldn't find type %s in %s", model.GoName, origin))
-	return false
+	if toX {
+		// * the first member declared in the oneof wins when several model fields are set
+		g.P("switch {")
+		for _, f := range fields {
+			modelField, _ := getModelField(structFields, f, toX)
+			g.P("case ", isSetCondition(g, modelField.Type(), "from."+modelField.Name(), model), ":")
+			g.P("to := &", f.GoIdent, "{}")
+			g.P("x.", oneof.GoName, " = to")
+			p.genFieldConversion(g, m, f, model, toX)
+		}
+		g.P("default:")
+		g.P("x.", oneof.GoName, " = nil")
+		g.P("}")
+	} else {
+		for _, f := range fields {
+			modelField, _ := getModelField(structFields, f, toX)
+			g.P("to.", modelField.Name(), " = ", model, "{}.", modelField.Name())
+		}
+		g.P("switch from := x.", oneof.GoName, ".(type) {")
+		for _, f := range fields {
+			g.P("case *", f.GoIdent, ":")
+			p.genFieldConversion(g, m, f, model, toX)
+		}
+		g.P("}")
+	}
+	return true
 }
 
-func (p *BimaPlugin) genFieldConversion(g *protogen.GeneratedFile, m *protogen.Message, field *protogen.Field, model protogen.GoIdent, toX bool) {
-	fieldType, pointer := fieldGoType(g, field)
-	if pointer {
-		fieldType = "*" + fieldType
+func (p *BimaPlugin) genListConversion(g *protogen.GeneratedFile, field *protogen.Field, fieldType string, modelField *modelField, model protogen.GoIdent, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+
+	// * pq arrays are slices underneath
+	slice, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported for repeated field %s on model %s", typeName(t), field.GoName, model.GoName)
+		return
 	}
+	pbElem, modelElem := fieldType[2:], slice.Elem()
 
-	fieldName := field.GoName
+	if field.Desc.Kind() == protoreflect.EnumKind {
+		p.genEnumListConversion(g, field, pbElem, modelElem, modelField, model, toName, fromName, toX)
+		return
+	}
 
-	structFields, ok := p.modelTypes[model.GoName]
+	if typePath(modelElem) == pbElem {
+		// * keeps nil as nil and empty as empty
+		g.P("to.", toName, " = from.", fromName)
+		return
+	}
+
+	if !isConvertible(pbElem, modelElem) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported for repeated field %s on model %s", typeName(t), field.GoName, model.GoName)
+		return
+	}
+
+	toElem := pbElem
+	if !toX {
+		toElem = goTypeString(g, modelElem)
+	}
+	g.P("if from.", fromName, " != nil {")
+	g.P("to.", toName, " = make([]", toElem, ", len(from.", fromName, "))")
+	g.P("for i, e := range from.", fromName, " {")
+	g.P("to.", toName, "[i] = ", toElem, "(e)")
+	g.P("}")
+	g.P("} else {")
+	g.P("to.", toName, " = nil")
+	g.P("}")
+}
+
+func (p *BimaPlugin) genNestedConversion(g *protogen.GeneratedFile, field *protogen.Field, modelField *modelField, model protogen.GoIdent, nested protogen.GoIdent, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	coreType, pointer := derefType(t)
+	if !isModelType(coreType, nested) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for %s", typeName(t), field.Message.GoIdent.GoName)
+		return
+	}
+	if !p.walkModelFields(field.Desc, nested) {
+		return
+	}
+
+	if toX {
+		if pointer {
+			g.P("if from.", fromName, " != nil {")
+			g.P("to.", toName, " = &", field.Message.GoIdent, "{}")
+			g.P("if err := to.", toName, ".Bundle(from.", fromName, "); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("} else {")
+			g.P("to.", toName, " = nil")
+			g.P("}")
+		} else {
+			g.P("to.", toName, " = &", field.Message.GoIdent, "{}")
+			g.P("if err := to.", toName, ".Bundle(&from.", fromName, "); err != nil {")
+			g.P("return err")
+			g.P("}")
+		}
+	} else {
+		if pointer {
+			g.P("if from.", fromName, " != nil {")
+			g.P("to.", toName, " = &", nested, "{}")
+			g.P("if err := from.", fromName, ".Bind(to.", toName, "); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("} else {")
+			g.P("to.", toName, " = nil")
+			g.P("}")
+		} else {
+			g.P("to.", toName, " = ", nested, "{}")
+			g.P("if from.", fromName, " != nil {")
+			g.P("if err := from.", fromName, ".Bind(&to.", toName, "); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("}")
+		}
+	}
+}
+
+func (p *BimaPlugin) genNestedListConversion(g *protogen.GeneratedFile, field *protogen.Field, modelField *modelField, model protogen.GoIdent, nested protogen.GoIdent, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	slice, ok := t.Underlying().(*types.Slice)
 	if !ok {
-		p.Error(errors.New("Something went wrong, please ask the author about this error"))
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported for repeated field %s on model %s", typeName(t), field.GoName, model.GoName)
+		return
+	}
+	coreType, pointer := derefType(slice.Elem())
+	if !isModelType(coreType, nested) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for %s", typeName(t), field.Message.GoIdent.GoName)
+		return
+	}
+	if !p.walkModelFields(field.Desc, nested) {
 		return
 	}
 
-	if field.Desc.IsList() {
-		// TODO
-	} else if field.Desc.Message() != nil {
-		parts := strings.Split(fieldType, ".")
-		pbType := parts[len(parts)-1]
-		typeStr, exists := structFields[fieldName]
-
-		if exists {
-			coreType, pointer := parseType(typeStr)
-			if pbFieldType, ok := wellKnownTypes[pbType]; ok {
-				if toX {
-					if v, ok := sqlTypes[coreType]; ok {
-						if pointer {
-							p.Error(errors.New(fmt.Sprintf("type %s is not supported", typeStr)))
-							return
-						}
-						g.P("if from.", fieldName, ".Valid {")
-						g.P("to.", fieldName, " = &", fieldType[1:], "{Value: from.", fieldName, ".", v, "}")
-						g.P("}")
-					} else {
-						if pointer {
-							g.P("if from.", fieldName, " != nil {")
-							g.P("to.", fieldName, " = &", fieldType[1:], "{Value: *from.", fieldName, "}")
-							g.P("}")
-						} else {
-							println(fmt.Sprintf("Warning: Please change protobuf type message's field regarding type of %s on model %s", fieldName, model.GoName))
-							// TODO: should we generate ?
-						}
-					}
-				} else {
-					if v, ok := sqlTypes[coreType]; ok {
-						if pointer {
-							p.Error(errors.New(fmt.Sprintf("type %s is not supported", typeStr)))
-							return
-						}
-						g.P("if from.", fieldName, " != nil {")
-						r := "from." + fieldName + ".Value"
-						g.QualifiedGoIdent(protogen.GoIdent{
-							GoImportPath: "database/sql",
-						})
-						r = coreType + "{" + v + ": " + r + ", Valid: true}"
-						g.P("to.", fieldName, " = ", r)
-						g.P("}")
-					} else {
-						if pointer {
-							g.P("if from.", fieldName, " != nil {")
-							r := "from." + fieldName + ".Value"
-							switch coreType {
-							case "string", "bool":
-								r = "&" + r
-							default:
-								if pbFieldType == coreType {
-									r = "&" + r
-								} else {
-									g.P(toLowerFirst(fieldName), " := ", coreType, "(", r, ")")
-									r = "&" + toLowerFirst(fieldName)
-								}
-							}
-							g.P("to.", fieldName, " = ", r)
-							g.P("}")
-						} else {
-							println(fmt.Sprintf("Warning: Please change protobuf type message's field regarding type of %s on model %s", fieldName, model.GoName))
-							// TODO: should we generate ?
-						}
-					}
+	g.P("if from.", fromName, " != nil {")
+	if toX {
+		g.P("to.", toName, " = make([]*", field.Message.GoIdent, ", len(from.", fromName, "))")
+		if pointer {
+			g.P("for i, e := range from.", fromName, " {")
+			g.P("if e != nil {")
+			g.P("to.", toName, "[i] = &", field.Message.GoIdent, "{}")
+			g.P("if err := to.", toName, "[i].Bundle(e); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("}")
+		} else {
+			g.P("for i := range from.", fromName, " {")
+			g.P("to.", toName, "[i] = &", field.Message.GoIdent, "{}")
+			g.P("if err := to.", toName, "[i].Bundle(&from.", fromName, "[i]); err != nil {")
+			g.P("return err")
+			g.P("}")
+		}
+		g.P("}")
+	} else {
+		if pointer {
+			g.P("to.", toName, " = make([]*", nested, ", len(from.", fromName, "))")
+		} else {
+			g.P("to.", toName, " = make([]", nested, ", len(from.", fromName, "))")
+		}
+		g.P("for i, e := range from.", fromName, " {")
+		g.P("if e != nil {")
+		if pointer {
+			g.P("to.", toName, "[i] = &", nested, "{}")
+			g.P("if err := e.Bind(to.", toName, "[i]); err != nil {")
+			g.P("return err")
+			g.P("}")
+		} else {
+			g.P("if err := e.Bind(&to.", toName, "[i]); err != nil {")
+			g.P("return err")
+			g.P("}")
+		}
+		g.P("}")
+		g.P("}")
+	}
+	g.P("} else {")
+	g.P("to.", toName, " = nil")
+	g.P("}")
+}
 
-				}
-			} else if pbType == "Timestamp" {
-				if toX {
-					g.QualifiedGoIdent(protogen.GoIdent{
-						GoImportPath: protogen.GoImportPath(ptypesImport),
-					})
-					if _, ok := sqlTypes[coreType]; ok {
-						if coreType != "sql.NullTime" {
-							p.Error(errors.New(fmt.Sprintf("type %s is not to be used for Timestamp", typeStr)))
-							return
-						}
-						if pointer {
-							p.Error(errors.New(fmt.Sprintf("type %s is not supported", typeStr)))
-							return
-						}
-						g.P("if from.", fieldName, ".Valid {")
-						g.P("to.", fieldName, ", _ = ", "ptypes.TimestampProto(from.", fieldName, ".Time)")
-						g.P("}")
-					} else {
-						if coreType != "time.Time" {
-							p.Error(errors.New(fmt.Sprintf("type %s is not to be used for Timestamp", typeStr)))
-							return
-						}
-						if pointer {
-							g.P("if from.", fieldName, " != nil {")
-							g.P("to.", fieldName, ", _ = ", "ptypes.TimestampProto(*from.", fieldName, ")")
-							g.P("}")
-						} else {
-							g.P("to.", fieldName, ", _ = ", "ptypes.TimestampProto(from.", fieldName, ")")
-						}
-					}
-				} else {
-					g.P("if from.", fieldName, " != nil {")
-					g.P("if from.", fieldName, ".IsValid() {")
-					if _, ok := sqlTypes[coreType]; ok {
-						if coreType != "sql.NullTime" {
-							p.Error(errors.New(fmt.Sprintf("type %s is not to be used for Timestamp", typeStr)))
-							return
-						}
-						if pointer {
-							p.Error(errors.New(fmt.Sprintf("type %s is not supported", typeStr)))
-							return
-						}
-						g.QualifiedGoIdent(protogen.GoIdent{
-							GoImportPath: "database/sql",
-						})
-						g.P("to.", fieldName, " = ", coreType, "{Time: from.", fieldName, ".AsTime(), Valid: true}")
-					} else {
-						if coreType != "time.Time" {
-							p.Error(errors.New(fmt.Sprintf("type %s is not to be used for Timestamp", typeStr)))
-							return
-						}
-						if pointer {
-							g.P("t := from.", fieldName, ".AsTime()")
-							g.P("to.", fieldName, " = &t")
-						} else {
-							g.P("to.", fieldName, " = from.", fieldName, ".AsTime()")
-						}
-					}
-					g.P("}")
-					g.P("}")
-				}
-			} else {
-				// TODO
-			}
+func (p *BimaPlugin) genMapConversion(g *protogen.GeneratedFile, field *protogen.Field, fieldType string, modelField *modelField, model protogen.GoIdent, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	pbKey, _ := fieldGoType(g, field.Message.Fields[0])
+	pbValue, _ := fieldGoType(g, field.Message.Fields[1])
+	if field.Message.Fields[1].Message != nil {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "map field %s with message values is not supported on model %s", field.GoName, model.GoName)
+		return
+	}
+
+	// * column types holding marshalled JSON, e.g datatypes.JSON or json.RawMessage
+	if isBytes(t) {
+		g.QualifiedGoIdent(protogen.GoIdent{
+			GoImportPath: "encoding/json",
+		})
+		if toX {
+			g.P("if len(from.", fromName, ") > 0 {")
+			g.P("m := ", fieldType, "{}")
+			g.P("if err := json.Unmarshal(from.", fromName, ", &m); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("to.", toName, " = m")
+		} else {
+			g.P("if from.", fromName, " != nil {")
+			g.P("b, err := json.Marshal(from.", fromName, ")")
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("to.", toName, " = b")
+		}
+		g.P("} else {")
+		g.P("to.", toName, " = nil")
+		g.P("}")
+		return
+	}
+
+	mt, ok := t.Underlying().(*types.Map)
+	if !ok {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported for map field %s on model %s", typeName(t), field.GoName, model.GoName)
+		return
+	}
+	sameKey, sameValue := typePath(mt.Key()) == pbKey, typePath(mt.Elem()) == pbValue
+
+	if sameKey && sameValue {
+		g.P("to.", toName, " = from.", fromName)
+		return
+	}
+
+	if (!sameKey && !isConvertible(pbKey, mt.Key())) || (!sameValue && !isConvertible(pbValue, mt.Elem())) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported for map field %s on model %s", typeName(t), field.GoName, model.GoName)
+		return
+	}
+
+	toKey, toValue := pbKey, pbValue
+	if !toX {
+		toKey, toValue = goTypeString(g, mt.Key()), goTypeString(g, mt.Elem())
+	}
+	k, e := "k", "e"
+	if !sameKey {
+		k = toKey + "(k)"
+	}
+	if !sameValue {
+		e = toValue + "(e)"
+	}
+	g.P("if from.", fromName, " != nil {")
+	g.P("to.", toName, " = make(map[", toKey, "]", toValue, ", len(from.", fromName, "))")
+	g.P("for k, e := range from.", fromName, " {")
+	g.P("to.", toName, "[", k, "] = ", e)
+	g.P("}")
+	g.P("} else {")
+	g.P("to.", toName, " = nil")
+	g.P("}")
+}
+
+func (p *BimaPlugin) genDurationConversion(g *protogen.GeneratedFile, field *protogen.Field, modelField *modelField, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	coreType, pointer := derefType(t)
+	newDuration := protogen.GoIdent{
+		GoName:       "New",
+		GoImportPath: field.Message.GoIdent.GoImportPath,
+	}
+
+	// * integer columns hold milliseconds unless told otherwise
+	unit, asUnit := "time.Millisecond", ".AsDuration().Milliseconds()"
+	if getFieldOptions(field.Desc).GetDurationUnit() == gorm.GormFieldOptions_SECONDS {
+		unit, asUnit = "time.Second", ".Seconds"
+	}
+
+	valueType := coreType
+	value, isNull := nullValue(coreType)
+	if isNull {
+		if pointer {
+			p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported", typeName(t))
+			return
+		}
+		valueType = value.Type()
+	}
+	isDuration := typePath(valueType) == "time.Duration"
+	if !isDuration && !isBasicKind(valueType, types.IsInteger) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for Duration", typeName(t))
+		return
+	}
+
+	if toX {
+		r := "from." + fromName
+		if isNull {
+			g.P("if from.", fromName, ".Valid {")
+			r += "." + value.Name()
+		} else if pointer {
+			g.P("if from.", fromName, " != nil {")
+			r = "*" + r
+		}
+		if isDuration {
+			g.P("to.", toName, " = ", newDuration, "(", r, ")")
+		} else {
+			g.QualifiedGoIdent(protogen.GoIdent{
+				GoImportPath: "time",
+			})
+			g.P("to.", toName, " = ", newDuration, "(time.Duration(", r, ") * ", unit, ")")
+		}
+		if isNull || pointer {
+			g.P("}")
 		}
 	} else {
-		typeStr, exists := structFields[fieldName]
-		if exists {
-			coreType, pointer := parseType(typeStr)
-			switch coreType {
-			case "string", "bool":
-				if pointer {
-					if toX {
-						g.P("to.", fieldName, " = *from.", fieldName)
-					} else {
-						g.P("to.", fieldName, " = &from.", fieldName)
-					}
-				} else {
-					g.P("to.", fieldName, " = from.", fieldName)
-				}
-			default:
-				if fieldType == coreType {
-					g.P("to.", fieldName, " = from.", fieldName)
-				} else {
-					if v, ok := sqlTypes[coreType]; ok {
-						if v == "Time" {
-							v = "time.Time"
-						}
-						println(fmt.Sprintf("Warning: Please change field %s on model %s to %s type, it wouldn't benefit anything", fieldName, model.GoName, toLowerFirst(v)))
-						// TODO: should we generate ?
-					} else {
-						if toX {
-							if pointer {
-								g.P("to.", fieldName, " = ", fieldType, "(*from.", fieldName, ")")
-							} else {
-								g.P("to.", fieldName, " = ", fieldType, "(from.", fieldName, ")")
-							}
-						} else {
-							fieldType = coreType
-							if pointer {
-								g.P(toLowerFirst(fieldName), " := ", fieldType, "(from.", fieldName, ")")
-								g.P("to.", fieldName, " = &", toLowerFirst(fieldName))
-							} else {
-								g.P("to.", fieldName, " = ", fieldType, "(from.", fieldName, ")")
-							}
-						}
-					}
-				}
-			}
-		} else if fieldName == "Id" {
-			// * always string
-			g.P("to.Id = from.Id")
+		g.P("if from.", fromName, " != nil {")
+		g.P("if from.", fromName, ".IsValid() {")
+		r := "from." + fromName + asUnit
+		if isDuration {
+			r = "from." + fromName + ".AsDuration()"
+		} else if typePath(valueType) != "int64" {
+			r = goTypeString(g, valueType) + "(" + r + ")"
+		}
+		if isNull {
+			g.P("to.", toName, " = ", goTypeString(g, coreType), "{", value.Name(), ": ", r, ", Valid: true}")
+		} else if pointer {
+			g.P("d := ", r)
+			g.P("to.", toName, " = &d")
+		} else {
+			g.P("to.", toName, " = ", r)
+		}
+		g.P("}")
+		g.P("}")
+	}
+}
+
+func (p *BimaPlugin) genStructConversion(g *protogen.GeneratedFile, field *protogen.Field, st structType, modelField *modelField, model protogen.GoIdent, toName string, fromName string, toX bool) {
+	t := modelField.Type()
+	if isBytes(t) {
+		g.QualifiedGoIdent(protogen.GoIdent{
+			GoImportPath: "google.golang.org/protobuf/encoding/protojson",
+		})
+		if toX {
+			g.P("if len(from.", fromName, ") > 0 {")
+			g.P("to.", toName, " = &", field.Message.GoIdent, "{}")
+			g.P("if err := protojson.Unmarshal(from.", fromName, ", to.", toName, "); err != nil {")
+			g.P("return err")
+			g.P("}")
+		} else {
+			g.P("if from.", fromName, " != nil {")
+			g.P("b, err := protojson.Marshal(from.", fromName, ")")
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("to.", toName, " = b")
+		}
+	} else if st.native(t) {
+		g.P("if from.", fromName, " != nil {")
+		if toX {
+			g.P("s, err := ", protogen.GoIdent{
+				GoName:       st.new,
+				GoImportPath: field.Message.GoIdent.GoImportPath,
+			}, "(from.", fromName, ")")
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("to.", toName, " = s")
+		} else {
+			g.P("to.", toName, " = from.", fromName, ".", st.as, "()")
+		}
+	} else {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not to be used for %s on model %s", typeName(t), field.Message.GoIdent.GoName, model.GoName)
+		return
+	}
+	g.P("} else {")
+	g.P("to.", toName, " = nil")
+	g.P("}")
+}
+
+func (p *BimaPlugin) genEnumConversion(g *protogen.GeneratedFile, field *protogen.Field, t types.Type, toName string, fromName string, toX bool) {
+	names, values := p.enumMapNames(g, field)
+
+	// * named string types, e.g type Status string, need a conversion
+	named := !types.Identical(t, types.Typ[types.String])
+
+	if toX {
+		key := "from." + fromName
+		if named {
+			key = "string(" + key + ")"
+		}
+		// * empty column is treated as the enum's zero value
+		g.P("if from.", fromName, " == \"\" {")
+		g.P("to.", toName, " = 0")
+		g.P("} else if e, ok := ", values, "[", key, "]; ok {")
+		g.P("to.", toName, " = ", field.Enum.GoIdent, "(e)")
+		g.P("} else {")
+		g.P("return fmt.Errorf(\"unknown value %q for enum ", field.Enum.Desc.FullName(), "\", from.", fromName, ")")
+		g.P("}")
+	} else {
+		g.P("if e, ok := ", names, "[int32(from.", fromName, ")]; ok {")
+		if named {
+			g.P("to.", toName, " = ", goTypeString(g, t), "(e)")
+		} else {
+			g.P("to.", toName, " = e")
+		}
+		g.P("} else {")
+		g.P("return fmt.Errorf(\"unknown value %d for enum ", field.Enum.Desc.FullName(), "\", from.", fromName, ")")
+		g.P("}")
+	}
+}
+
+// genEnumListConversion converts repeated enums element by element to integers, or to strings
+// like genEnumConversion does
+func (p *BimaPlugin) genEnumListConversion(g *protogen.GeneratedFile, field *protogen.Field, pbElem string, modelElem types.Type, modelField *modelField, model protogen.GoIdent, toName string, fromName string, toX bool) {
+	isString := isBasicKind(modelElem, types.IsString)
+	if !isString && !isBasicKind(modelElem, types.IsInteger) {
+		p.diagnose(severityError, field.Desc, modelField.Pos(), "type %s is not supported for repeated field %s on model %s", typeName(modelField.Type()), field.GoName, model.GoName)
+		return
+	}
+
+	toElem := pbElem
+	if !toX {
+		toElem = goTypeString(g, modelElem)
+	}
+	g.P("if from.", fromName, " != nil {")
+	g.P("to.", toName, " = make([]", toElem, ", len(from.", fromName, "))")
+	g.P("for i, e := range from.", fromName, " {")
+	switch {
+	case !isString:
+		g.P("to.", toName, "[i] = ", toElem, "(e)")
+	case toX:
+		_, values := p.enumMapNames(g, field)
+		g.P("if e == \"\" {")
+		g.P("to.", toName, "[i] = 0")
+		g.P("} else if v, ok := ", values, "[string(e)]; ok {")
+		g.P("to.", toName, "[i] = ", toElem, "(v)")
+		g.P("} else {")
+		g.P("return fmt.Errorf(\"unknown value %q for enum ", field.Enum.Desc.FullName(), "\", e)")
+		g.P("}")
+	default:
+		names, _ := p.enumMapNames(g, field)
+		g.P("if v, ok := ", names, "[int32(e)]; ok {")
+		g.P("to.", toName, "[i] = ", toElem, "(v)")
+		g.P("} else {")
+		g.P("return fmt.Errorf(\"unknown value %d for enum ", field.Enum.Desc.FullName(), "\", e)")
+		g.P("}")
+	}
+	g.P("}")
+	g.P("} else {")
+	g.P("to.", toName, " = nil")
+	g.P("}")
+}
+
+// enumMapNames returns the maps between an enum's values and names its conversions use,
+// protoc-gen-go's own or ones declared by genEnumMaps on (gorm.field).enum_case
+func (p *BimaPlugin) enumMapNames(g *protogen.GeneratedFile, field *protogen.Field) (names string, values string) {
+	names = g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       field.Enum.GoIdent.GoName + "_name",
+		GoImportPath: field.Enum.GoIdent.GoImportPath,
+	})
+	values = g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       field.Enum.GoIdent.GoName + "_value",
+		GoImportPath: field.Enum.GoIdent.GoImportPath,
+	})
+	if enumCase := getFieldOptions(field.Desc).GetEnumCase(); enumCase != gorm.GormFieldOptions_ORIGINAL {
+		prefix := "_" + field.Enum.GoIdent.GoName + "_" + strings.ToLower(enumCase.String())
+		p.enumMaps[prefix] = enumMap{enum: field.Enum, enumCase: enumCase}
+		names, values = prefix+"_name", prefix+"_value"
+	}
+	g.QualifiedGoIdent(protogen.GoIdent{
+		GoImportPath: "fmt",
+	})
+	return names, values
+}
+
+// genEnumMaps declares the enum maps the file's conversions use, once per package since files
+// of a package may share them
+func (p *BimaPlugin) genEnumMaps(g *protogen.GeneratedFile, importPath protogen.GoImportPath) {
+	declared := p.enumMapDecls[importPath]
+	if declared == nil {
+		declared = make(map[string]bool)
+		p.enumMapDecls[importPath] = declared
+	}
+	prefixes := make([]string, 0, len(p.enumMaps))
+	for prefix := range p.enumMaps {
+		if !declared[prefix] {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		declared[prefix] = true
+		em := p.enumMaps[prefix]
+		g.P("var ", prefix, "_name = map[int32]string{")
+		for _, v := range em.enum.Values {
+			g.P(v.Desc.Number(), ": \"", em.name(v), "\",")
+		}
+		g.P("}")
+		g.P()
+		g.P("var ", prefix, "_value = map[string]int32{")
+		for _, v := range em.enum.Values {
+			g.P("\"", em.name(v), "\": ", v.Desc.Number(), ",")
 		}
+		g.P("}")
+		g.P()
 	}
 }
 
@@ -613,14 +1388,181 @@ func getMessageOptions(m protoreflect.MessageDescriptor) *gorm.GormMessageOption
 		return nil
 	}
 	ext := proto.GetExtension(m.Options(), gorm.E_Opts)
-	opts, ok := ext.(*gorm.GormMessageOptions)
-	if !ok {
-		println(fmt.Sprintf("extension is %T; want an GormMessageOptions", ext))
+	opts, _ := ext.(*gorm.GormMessageOptions)
+	return opts
+}
+
+func getMethodOptions(m protoreflect.MethodDescriptor) *gorm.GormMethodOptions {
+	if m.Options() == nil {
+		return nil
+	}
+	if !proto.HasExtension(m.Options(), gorm.E_Method) {
+		return nil
+	}
+	ext := proto.GetExtension(m.Options(), gorm.E_Method)
+	opts, _ := ext.(*gorm.GormMethodOptions)
+	return opts
+}
+
+// getPaginationField returns the meta or pagination message field of a paginated response
+func getPaginationField(m *protogen.Message) *protogen.Field {
+	for _, f := range m.Fields {
+		switch f.Desc.Name() {
+		case "meta", "pagination":
+			if f.Message != nil && !f.Desc.IsList() && !f.Desc.IsMap() {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func hasField(m *protogen.Message, name protoreflect.Name) bool {
+	return m.Desc.Fields().ByName(name) != nil
+}
+
+func isIntegerKind(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+	return false
+}
+
+// getResponseOptions returns the (gorm.response) option of a message, or its file's (gorm.file_response)
+func getResponseOptions(m protoreflect.MessageDescriptor) *gorm.GormResponseOptions {
+	if m.Options() != nil && proto.HasExtension(m.Options(), gorm.E_Response) {
+		opts, _ := proto.GetExtension(m.Options(), gorm.E_Response).(*gorm.GormResponseOptions)
+		return opts
+	}
+	if f := m.ParentFile(); f.Options() != nil && proto.HasExtension(f.Options(), gorm.E_FileResponse) {
+		opts, _ := proto.GetExtension(f.Options(), gorm.E_FileResponse).(*gorm.GormResponseOptions)
+		return opts
+	}
+	return nil
+}
+
+func getFieldOptions(f protoreflect.FieldDescriptor) *gorm.GormFieldOptions {
+	if f.Options() == nil {
 		return nil
 	}
+	if !proto.HasExtension(f.Options(), gorm.E_Field) {
+		return nil
+	}
+	ext := proto.GetExtension(f.Options(), gorm.E_Field)
+	opts, _ := ext.(*gorm.GormFieldOptions)
 	return opts
 }
 
+// getModelField returns the model's field of a proto field, false when the field is skipped
+// on the given direction or the model has no such field
+func getModelField(sf structFields, field *protogen.Field, toX bool) (*modelField, bool) {
+	if isSkipped(field, toX) {
+		return nil, false
+	}
+	if name := getFieldOptions(field.Desc).GetModelField(); name != "" {
+		f, ok := sf[name]
+		return f, ok
+	}
+	return matchModelField(sf, field)
+}
+
+// isSkipped reports whether a field is left out on the given direction by its options
+func isSkipped(field *protogen.Field, toX bool) bool {
+	opts := getFieldOptions(field.Desc)
+	return opts.GetIgnore() || (opts.GetReadonly() && !toX) || (opts.GetWriteonly() && toX)
+}
+
+// skipField reports a proto field which isn't converted from/to its model, it fails the
+// generation on strict mode while otherwise only warnings get reported
+func (p *BimaPlugin) skipField(field *protogen.Field, model protogen.GoIdent, modelField *modelField, reason string, warn bool) {
+	pos := token.NoPos
+	if modelField != nil {
+		pos = modelField.Pos()
+	}
+	if p.strict {
+		p.diagnose(severityError, field.Desc, pos, "field %s of message %s isn't converted with model %s: %s",
+			field.Desc.Name(), field.Parent.Desc.FullName(), model.GoName, reason)
+	} else if warn {
+		p.diagnose(severityWarning, field.Desc, pos, "%s", reason)
+	}
+}
+
+// checkModelFields fails the generation when a field declared on the model isn't converted
+// from/to the message, fields promoted from embedded structs are left out
+func (p *BimaPlugin) checkModelFields(m *protogen.Message, model protogen.GoIdent) {
+	structFields, ok := p.modelTypes[model.GoName]
+	if !ok {
+		return
+	}
+
+	matched := map[*modelField]bool{}
+	for _, f := range m.Fields {
+		for _, toX := range []bool{false, true} {
+			if modelField, ok := getModelField(structFields, f, toX); ok {
+				matched[modelField] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(structFields))
+	for name := range structFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if f := structFields[name]; !f.promoted && !f.Embedded() && !matched[f] {
+			p.diagnose(severityError, m.Desc, f.Pos(), "field %s of model %s isn't converted with message %s",
+				name, model.GoName, m.Desc.FullName())
+		}
+	}
+}
+
+func isOneofField(field *protogen.Field) bool {
+	return field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+}
+
+// isSetCondition renders a condition telling whether a model field holds a non zero value,
+// types without a condition of their own are compared to the zero value so must be comparable
+func isSetCondition(g *protogen.GeneratedFile, t types.Type, name string, model protogen.GoIdent) string {
+	if _, ok := nullValue(t); ok {
+		return name + ".Valid"
+	}
+	if typePath(t) == "time.Time" {
+		return "!" + name + ".IsZero()"
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return name + " != nil"
+	case *types.Slice, *types.Map:
+		return "len(" + name + ") > 0"
+	}
+	switch {
+	case isBasicKind(t, types.IsString):
+		return name + " != \"\""
+	case isBasicKind(t, types.IsBoolean):
+		return name
+	case isBasicKind(t, types.IsNumeric):
+		return name + " != 0"
+	}
+	return name + " != (" + g.QualifiedGoIdent(model) + "{})." + name[strings.LastIndex(name, ".")+1:]
+}
+
+// hasSetCondition reports whether isSetCondition has a condition of its own for the type
+func hasSetCondition(t types.Type) bool {
+	if _, ok := nullValue(t); ok || typePath(t) == "time.Time" {
+		return true
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Slice, *types.Map, *types.Basic:
+		return true
+	}
+	return false
+}
+
 func getModelIdent(md protoreflect.MessageDescriptor) (protogen.GoIdent, bool) {
 	if opt := getMessageOptions(md).GetModel(); opt != "" {
 		if i := strings.Index(opt, ";"); i >= 0 {
@@ -676,18 +1618,17 @@ func fieldGoType(g *protogen.GeneratedFile, field *protogen.Field) (goType strin
 	return goType, pointer
 }
 
-func parseType(str string) (goType string, pointer bool) {
-	if len(str) > 0 && str[:1] == "*" {
-		return str[1:], true
+func isNumericType(str string) bool {
+	switch str {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
 	}
-	return str, false
-}
-
-func toLowerFirst(str string) string {
-	return strings.ToLower(str[:1]) + str[1:]
+	return false
 }
 
-func getPackageName() string {
-	mod, _ := ioutil.ReadFile("go.mod")
+func getPackageName(dir string) string {
+	mod, _ := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
 	return modfile.ModulePath(mod)
 }
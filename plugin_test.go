@@ -0,0 +1,825 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gorm "github.com/crowdeco/protoc-gen-bima/options"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const testModule = "example.com/gen"
+
+// * modules the generated code imports, the plugin's own packages come from this tree
+const testGoMod = `module example.com/gen
+
+go 1.15
+
+require (
+	github.com/crowdeco/protoc-gen-bima v0.0.0
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.25.0
+	gorm.io/gorm v1.25.0
+)
+
+replace github.com/crowdeco/protoc-gen-bima => %s
+`
+
+// * files a test proto may import, by a message of each
+var testDependencies = []proto.Message{
+	&descriptorpb.FileDescriptorProto{},
+	&durationpb.Duration{},
+	&structpb.Struct{},
+	&timestamppb.Timestamp{},
+	&wrapperspb.StringValue{},
+	&gorm.GormMessageOptions{},
+}
+
+// genTest is a proto file generated into its own package of the test module, e.g example.com/gen/lists
+type genTest struct {
+	name     string
+	plugin   BimaPlugin
+	proto    string            // * FileDescriptorProto in text format, name, package and go_package are filled in
+	files    map[string]string // * sources of the package written before generating, e.g models/item.go
+	contains []string          // * snippets the generated .pb.bima.go must have
+	lacks    []string          // * and the ones it mustn't
+}
+
+// newTestModule creates the module generator tests write their packages to, go.sum is
+// filled in as the go command needs it
+func newTestModule(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("generator tests build their output")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(fmt.Sprintf(testGoMod, root)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// * go/packages runs the go command too when the plugin loads models
+	flags, ok := os.LookupEnv("GOFLAGS")
+	os.Setenv("GOFLAGS", "-mod=mod")
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv("GOFLAGS", flags)
+		} else {
+			os.Unsetenv("GOFLAGS")
+		}
+	})
+	return dir
+}
+
+// generate runs protoc-gen-go and the plugin over the test's proto, writes their output to the
+// module and returns the generated .pb.bima.go
+func (tt genTest) generate(t *testing.T, dir string) (string, error) {
+	t.Helper()
+	for name, src := range tt.files {
+		path := filepath.Join(dir, tt.name, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(tt.proto), fd); err != nil {
+		t.Fatal(err)
+	}
+	fd.Name = proto.String(tt.name + ".proto")
+	fd.Package = proto.String(tt.name)
+	if fd.Options == nil {
+		fd.Options = &descriptorpb.FileOptions{}
+	}
+	fd.Options.GoPackage = proto.String(testModule + "/" + tt.name + ";" + tt.name)
+
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.GetName()},
+		Parameter:      proto.String("module=" + testModule),
+	}
+	for _, m := range testDependencies {
+		// * go_package as protoc ships it nowadays, older releases point to github.com/golang/protobuf
+		dep := protodesc.ToFileDescriptorProto(m.ProtoReflect().Descriptor().ParentFile())
+		dep.Options.GoPackage = proto.String(reflect.TypeOf(m).Elem().PkgPath())
+		req.ProtoFile = append(req.ProtoFile, dep)
+	}
+	req.ProtoFile = append(req.ProtoFile, fd)
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen.SupportedFeatures = gengo.SupportedFeatures
+	for _, f := range gen.Files {
+		if f.Generate {
+			gengo.GenerateFile(gen, f)
+			genServiceStubs(gen, f)
+		}
+	}
+
+	p := tt.plugin
+	if p.modelRoot == "" {
+		p.modelRoot = dir
+	}
+	p.Generate(gen)
+	resp := gen.Response()
+	if resp.Error != nil {
+		return "", fmt.Errorf("%s", resp.GetError())
+	}
+
+	var out string
+	for _, f := range resp.File {
+		path := filepath.Join(dir, filepath.FromSlash(f.GetName()))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(f.GetContent()), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(f.GetName(), ".pb.bima.go") {
+			out = f.GetContent()
+		}
+	}
+	for _, s := range tt.contains {
+		if !strings.Contains(out, s) {
+			t.Errorf("%s: generated code doesn't contain %q:\n%s", tt.name, s, out)
+		}
+	}
+	for _, s := range tt.lacks {
+		if strings.Contains(out, s) {
+			t.Errorf("%s: generated code contains %q:\n%s", tt.name, s, out)
+		}
+	}
+	return out, nil
+}
+
+// genServiceStubs stands in for protoc-gen-go-grpc, declaring the server interfaces emit=server implements
+func genServiceStubs(gen *protogen.Plugin, f *protogen.File) {
+	if len(f.Services) == 0 {
+		return
+	}
+	g := gen.NewGeneratedFile(f.GeneratedFilenamePrefix+"_grpc.pb.go", f.GoImportPath)
+	g.P("package ", f.GoPackageName)
+	context := g.QualifiedGoIdent(protogen.GoIdent{GoName: "Context", GoImportPath: "context"})
+	for _, s := range f.Services {
+		g.P("type ", s.GoName, "Server interface {")
+		for _, m := range s.Methods {
+			g.P(m.GoName, "(", context, ", *", m.Input.GoIdent, ") (*", m.Output.GoIdent, ", error)")
+		}
+		g.P("}")
+		g.P("type Unimplemented", s.GoName, "Server struct{}")
+		for _, m := range s.Methods {
+			g.P("func (Unimplemented", s.GoName, "Server) ", m.GoName, "(", context, ", *", m.Input.GoIdent, ") (*", m.Output.GoIdent, ", error) {")
+			g.P("return nil, nil")
+			g.P("}")
+		}
+	}
+}
+
+// goBuild builds and vets every package of the module
+func goBuild(t *testing.T, dir string) {
+	t.Helper()
+	for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+// * conversions generated by default, each test is a package of the module
+var conversionTests = []genTest{
+	{
+		name: "lists",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Item"
+  options { [gorm.opts] { model: "example.com/gen/lists/models;Item" } }
+  field { name: "tags" number: 1 label: LABEL_REPEATED type: TYPE_STRING }
+  field { name: "scores" number: 2 label: LABEL_REPEATED type: TYPE_INT32 }
+  field { name: "states" number: 3 label: LABEL_REPEATED type: TYPE_ENUM type_name: ".lists.State" }
+  field { name: "labels" number: 4 label: LABEL_REPEATED type: TYPE_ENUM type_name: ".lists.State" options { [gorm.field] { enum_case: LOWER } } }
+}
+enum_type {
+  name: "State"
+  value { name: "DRAFT" number: 0 }
+  value { name: "PUBLISHED" number: 1 }
+}`,
+		files: map[string]string{"models/item.go": `package models
+
+type Label string
+
+type Item struct {
+	Tags   []string
+	Scores []int64
+	States []int16
+	Labels []Label
+}
+`},
+		contains: []string{
+			"to.Tags = from.Tags",
+			"to.Scores[i] = int64(e)",
+			"to.States[i] = int16(e)",
+			"to.States[i] = State(e)",
+			"_State_lower_value[string(e)]",
+			"to.Labels[i] = models.Label(v)",
+		},
+	},
+	{
+		name: "nested",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Order"
+  options { [gorm.opts] { model: "example.com/gen/nested/models;Order" } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "customer" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".nested.Customer" }
+  field { name: "address" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".nested.Address" }
+}
+message_type {
+  name: "Customer"
+  options { [gorm.opts] { model: "example.com/gen/nested/models;Customer" } }
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type {
+  name: "Address"
+  options { [gorm.opts] { model: "example.com/gen/nested/models;Address" } }
+  field { name: "city" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}`,
+		files: map[string]string{"models/order.go": `package models
+
+type Order struct {
+	ID       string
+	Customer Customer
+	Address  *Address
+}
+
+type Customer struct {
+	Name string
+}
+
+type Address struct {
+	City string
+}
+`},
+		contains: []string{
+			"from.Customer.Bind(&to.Customer)",
+			"to.Customer.Bundle(&from.Customer)",
+			"from.Address.Bind(to.Address)",
+			"to.Address.Bundle(from.Address)",
+		},
+	},
+	{
+		name: "hasmany",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Cart"
+  options { [gorm.opts] { model: "example.com/gen/hasmany/models;Cart" } }
+  field { name: "items" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".hasmany.Item" }
+  field { name: "notes" number: 2 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".hasmany.Note" }
+}
+message_type {
+  name: "Item"
+  options { [gorm.opts] { model: "example.com/gen/hasmany/models;Item" } }
+  field { name: "sku" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type {
+  name: "Note"
+  options { [gorm.opts] { model: "example.com/gen/hasmany/models;Note" } }
+  field { name: "text" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}`,
+		files: map[string]string{"models/cart.go": `package models
+
+type Cart struct {
+	Items []Item
+	Notes []*Note
+}
+
+type Item struct {
+	Sku string
+}
+
+type Note struct {
+	Text string
+}
+`},
+		contains: []string{
+			"to.Items = make([]models.Item, len(from.Items))",
+			"e.Bind(&to.Items[i])",
+			"to.Items[i].Bundle(&from.Items[i])",
+			"to.Notes = make([]*models.Note, len(from.Notes))",
+			"e.Bind(to.Notes[i])",
+			"to.Notes[i].Bundle(e)",
+		},
+	},
+	{
+		name: "enums",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Task"
+  options { [gorm.opts] { model: "example.com/gen/enums/models;Task" } }
+  field { name: "state" number: 1 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".enums.State" }
+  field { name: "state_name" number: 2 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".enums.State" }
+  field { name: "phase" number: 3 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".enums.State" options { [gorm.field] { enum_case: SNAKE } } }
+  field { name: "level" number: 4 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".enums.State" options { [gorm.field] { enum_case: LOWER } } }
+}
+enum_type {
+  name: "State"
+  value { name: "Draft" number: 0 }
+  value { name: "InReview" number: 1 }
+}`,
+		files: map[string]string{"models/task.go": `package models
+
+type Phase string
+
+type Task struct {
+	State     int
+	StateName string
+	Phase     Phase
+	Level     string
+}
+`},
+		contains: []string{
+			"to.State = int(from.State)",
+			"to.State = State(from.State)",
+			"State_value[from.StateName]",
+			"State_name[int32(from.StateName)]",
+			"_State_snake_value[string(from.Phase)]",
+			"to.Phase = models.Phase(e)",
+			`"in_review": 1`,
+			`"inreview": 1`,
+		},
+	},
+	{
+		name: "maps",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Entry"
+  options { [gorm.opts] { model: "example.com/gen/maps/models;Entry" } }
+  field { name: "labels" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".maps.Entry.LabelsEntry" }
+  field { name: "counts" number: 2 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".maps.Entry.CountsEntry" }
+  field { name: "attributes" number: 3 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".maps.Entry.AttributesEntry" }
+  field { name: "raw" number: 4 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".maps.Entry.RawEntry" }
+  nested_type {
+    name: "LabelsEntry"
+    options { map_entry: true }
+    field { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+    field { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  }
+  nested_type {
+    name: "CountsEntry"
+    options { map_entry: true }
+    field { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+    field { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 }
+  }
+  nested_type {
+    name: "AttributesEntry"
+    options { map_entry: true }
+    field { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+    field { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  }
+  nested_type {
+    name: "RawEntry"
+    options { map_entry: true }
+    field { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+    field { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  }
+}`,
+		files: map[string]string{"models/entry.go": `package models
+
+import "encoding/json"
+
+type Entry struct {
+	Labels     map[string]string
+	Counts     map[int64]int
+	Attributes json.RawMessage
+	Raw        []byte
+}
+`},
+		contains: []string{
+			"to.Labels = from.Labels",
+			"to.Counts = make(map[int64]int, len(from.Counts))",
+			"to.Counts[int64(k)] = int(e)",
+			"to.Counts[int32(k)] = int64(e)",
+			"json.Marshal(from.Attributes)",
+			"json.Unmarshal(from.Raw, &m)",
+		},
+	},
+	{
+		name: "structs",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+dependency: "google/protobuf/struct.proto"
+message_type {
+  name: "Setting"
+  options { [gorm.opts] { model: "example.com/gen/structs/models;Setting" } }
+  field { name: "data" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Struct" }
+  field { name: "options" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Struct" }
+  field { name: "value" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Value" }
+  field { name: "list" number: 4 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.ListValue" }
+  field { name: "raw_list" number: 5 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.ListValue" }
+}`,
+		files: map[string]string{"models/setting.go": `package models
+
+import "encoding/json"
+
+type Setting struct {
+	Data    json.RawMessage
+	Options map[string]interface{}
+	Value   interface{}
+	List    []interface{}
+	RawList []byte
+}
+`},
+		contains: []string{
+			"protojson.Marshal(from.Data)",
+			"protojson.Unmarshal(from.Data, to.Data)",
+			"to.Options = from.Options.AsMap()",
+			"structpb.NewStruct(from.Options)",
+			"structpb.NewValue(from.Value)",
+			"to.List = from.List.AsSlice()",
+			"protojson.Unmarshal(from.RawList, to.RawList)",
+		},
+	},
+	{
+		name: "durations",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+dependency: "google/protobuf/duration.proto"
+message_type {
+  name: "Job"
+  options { [gorm.opts] { model: "example.com/gen/durations/models;Job" } }
+  field { name: "timeout" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Duration" }
+  field { name: "delay" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Duration" }
+  field { name: "ttl" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Duration" }
+  field { name: "interval" number: 4 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Duration" options { [gorm.field] { duration_unit: SECONDS } } }
+  field { name: "grace" number: 5 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Duration" }
+}`,
+		files: map[string]string{"models/job.go": `package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Job struct {
+	Timeout  time.Duration
+	Delay    *time.Duration
+	TTL      int64 ` + "`gorm:\"column:ttl\"`" + `
+	Interval int32
+	Grace    sql.NullInt64
+}
+`},
+		contains: []string{
+			"to.Timeout = durationpb.New(from.Timeout)",
+			"to.Timeout = from.Timeout.AsDuration()",
+			"to.Delay = durationpb.New(*from.Delay)",
+			"to.TTL = from.Ttl.AsDuration().Milliseconds()",
+			"durationpb.New(time.Duration(from.TTL) * time.Millisecond)",
+			"to.Interval = int32(from.Interval.Seconds)",
+			"durationpb.New(time.Duration(from.Interval) * time.Second)",
+			"to.Grace = sql.NullInt64{Int64: from.Grace.AsDuration().Milliseconds(), Valid: true}",
+		},
+	},
+	{
+		name: "timestamps",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+dependency: "google/protobuf/timestamp.proto"
+message_type {
+  name: "Event"
+  options { [gorm.opts] { model: "example.com/gen/timestamps/models;Event" } }
+  field { name: "starts_at" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  field { name: "ends_at" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  field { name: "deleted_at" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+}`,
+		files: map[string]string{"models/event.go": `package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Event struct {
+	StartsAt  time.Time
+	EndsAt    *time.Time
+	DeletedAt sql.NullTime
+}
+`},
+		contains: []string{
+			"to.StartsAt = timestamppb.New(from.StartsAt)",
+			"to.StartsAt = from.StartsAt.AsTime()",
+			"to.EndsAt = timestamppb.New(*from.EndsAt)",
+			"if from.DeletedAt.Valid {",
+			"to.DeletedAt = sql.NullTime{Time: from.DeletedAt.AsTime(), Valid: true}",
+		},
+		lacks: []string{"ptypes"},
+	},
+	{
+		name: "oneofs",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Payment"
+  options { [gorm.opts] { model: "example.com/gen/oneofs/models;Payment" } }
+  field { name: "card" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 }
+  field { name: "transfer" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 oneof_index: 0 }
+  field { name: "cash" number: 3 label: LABEL_OPTIONAL type: TYPE_BOOL oneof_index: 0 }
+  oneof_decl { name: "method" }
+}`,
+		files: map[string]string{"models/payment.go": `package models
+
+type Payment struct {
+	Card     *string
+	Transfer int64
+	Cash     bool
+}
+`},
+		contains: []string{
+			"to.Card = models.Payment{}.Card",
+			"switch from := x.Method.(type) {",
+			"case *Payment_Transfer:",
+			"case from.Card != nil:",
+			"case from.Transfer != 0:",
+			"case from.Cash:",
+			"to := &Payment_Cash{}",
+			"x.Method = nil",
+		},
+	},
+	{
+		// * fields named like keywords or to and from, with optional fields and wrappers
+		name: "keywords",
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+dependency: "google/protobuf/wrappers.proto"
+message_type {
+  name: "Rule"
+  options { [gorm.opts] { model: "example.com/gen/keywords/models;Rule" } }
+  field { name: "type" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 oneof_index: 0 proto3_optional: true }
+  field { name: "default" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field { name: "range" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Int32Value" }
+  field { name: "func" number: 4 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "to" number: 5 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field { name: "from" number: 6 label: LABEL_OPTIONAL type: TYPE_INT32 oneof_index: 1 proto3_optional: true }
+  field { name: "note" number: 7 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.StringValue" }
+  field { name: "label" number: 8 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.StringValue" }
+  field { name: "count" number: 9 label: LABEL_OPTIONAL type: TYPE_INT64 oneof_index: 2 proto3_optional: true }
+  oneof_decl { name: "_type" }
+  oneof_decl { name: "_from" }
+  oneof_decl { name: "_count" }
+}`,
+		files: map[string]string{"models/rule.go": `package models
+
+import "database/sql"
+
+type Rule struct {
+	Type    *int
+	Default *int64
+	Range   *int
+	Func    string
+	To      *int64
+	From    *int
+	Note    *string
+	Label   sql.NullString
+	Count   *int64
+}
+`},
+		contains: []string{
+			"v := int(*from.Type)",
+			"v := int64(from.Default)",
+			"v := int(from.Range.Value)",
+			"to.Func = from.Func",
+			"v := int64(from.To)",
+			"v := int(*from.From)",
+			"to.Note = &from.Note.Value",
+			"to.Label = sql.NullString{String: from.Label.Value, Valid: true}",
+			"to.Count = from.Count",
+		},
+	},
+}
+
+func TestConversions(t *testing.T) {
+	dir := newTestModule(t)
+	for _, tt := range conversionTests {
+		if _, err := tt.generate(t, dir); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+	goBuild(t, dir)
+}
+
+func TestRepository(t *testing.T) {
+	dir := newTestModule(t)
+	tt := genTest{
+		name:   "repository",
+		plugin: BimaPlugin{emit: map[string]bool{emitConversions: true, emitRepository: true}},
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+dependency: "google/protobuf/timestamp.proto"
+dependency: "google/protobuf/wrappers.proto"
+message_type {
+  name: "Article"
+  options { [gorm.opts] { model: "example.com/gen/repository/models;Article" } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "title" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "summary" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.StringValue" }
+  field { name: "rank" number: 4 label: LABEL_OPTIONAL type: TYPE_INT32 oneof_index: 0 proto3_optional: true }
+  field { name: "published_at" number: 5 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  field { name: "opened_at" number: 6 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  field { name: "created_at" number: 7 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  field { name: "updated_at" number: 8 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  field { name: "deleted_at" number: 9 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" }
+  oneof_decl { name: "_rank" }
+}`,
+		files: map[string]string{"models/article.go": `package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Base struct {
+	ID        string ` + "`gorm:\"primaryKey\"`" + `
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+type Article struct {
+	Base
+	Title       string
+	Summary     *string
+	Rank        *int32
+	PublishedAt *time.Time
+	OpenedAt    time.Time ` + "`gorm:\"autoCreateTime\"`" + `
+}
+`},
+		contains: []string{
+			`if x.GetId() == "" {`,
+			`columns := []string{"Title"}`,
+			`columns = append(columns, "Summary")`,
+			`columns = append(columns, "Rank")`,
+			`columns = append(columns, "PublishedAt")`,
+			`columns = append(columns, "UpdatedAt")`,
+			`Select(columns).Updates(&v)`,
+		},
+		lacks: []string{`"CreatedAt"`, `"DeletedAt"`, `"OpenedAt"`, `"ID"`},
+	}
+	if _, err := tt.generate(t, dir); err != nil {
+		t.Fatal(err)
+	}
+	goBuild(t, dir)
+}
+
+func TestServer(t *testing.T) {
+	dir := newTestModule(t)
+	tt := genTest{
+		name: "server",
+		plugin: BimaPlugin{emit: map[string]bool{
+			emitConversions: true, emitResponses: true, emitRepository: true, emitServer: true,
+		}},
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Note"
+  options { [gorm.opts] { model: "example.com/gen/server/models;Note" } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 proto3_optional: true }
+  field { name: "title" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  oneof_decl { name: "_id" }
+}
+message_type {
+  name: "NoteRequest"
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 proto3_optional: true }
+  oneof_decl { name: "_id" }
+}
+message_type {
+  name: "NoteRef"
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type {
+  name: "NoteResponse"
+  field { name: "code" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field { name: "data" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".server.Note" }
+  field { name: "message" number: 3 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type {
+  name: "NoteLookupResponse"
+  options { [gorm.response] { statuses: "StatusOK" statuses: "StatusNotFound" } }
+  field { name: "code" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field { name: "data" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".server.Note" }
+}
+service {
+  name: "Notes"
+  method { name: "GetNote" input_type: ".server.NoteRequest" output_type: ".server.NoteResponse" }
+  method { name: "FindNote" input_type: ".server.NoteRequest" output_type: ".server.NoteLookupResponse" }
+  method { name: "DeleteNote" input_type: ".server.NoteRef" output_type: ".server.NoteResponse" }
+  method { name: "UpdateNote" input_type: ".server.Note" output_type: ".server.NoteResponse" }
+}`,
+		files: map[string]string{"models/note.go": `package models
+
+type Note struct {
+	ID    string
+	Title string
+}
+`},
+		contains: []string{
+			"if in.Id == nil {",
+			`return NoteResponseStatusBadRequest(nil, errors.New("id is required"))`,
+			`return nil, status.Error(codes.InvalidArgument, "id is required")`,
+			"FindByID(ctx, *in.Id)",
+			"Delete(ctx, in.Id)",
+			"h.NoteRepository.Update(ctx, in)",
+		},
+	}
+	if _, err := tt.generate(t, dir); err != nil {
+		t.Fatal(err)
+	}
+	goBuild(t, dir)
+}
+
+func TestMigrationsOutput(t *testing.T) {
+	dir := newTestModule(t)
+	tt := genTest{
+		name:   "ledger",
+		plugin: BimaPlugin{emit: map[string]bool{emitMigrations: true}},
+		proto: `
+syntax: "proto3"
+dependency: "options/gorm.proto"
+message_type {
+  name: "Entry"
+  options { [gorm.opts] { model: "example.com/gen/ledger/models;Entry" } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "amount" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 }
+}`,
+		files: map[string]string{"models/entry.go": `package models
+
+type Entry struct {
+	ID     string
+	Amount int64
+}
+`},
+	}
+	// * module= strips the module from the path, so the migration lands in model_root's migrations_dir
+	if _, err := tt.generate(t, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "migrations", "0001_ledger.sql")); err != nil {
+		t.Error(err)
+	}
+
+	// * a model_root outside module= can't be written to
+	root := filepath.Join(dir, "other")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tt.plugin.modelRoot = root
+	_, err := tt.generate(t, dir)
+	if err == nil || !strings.Contains(err.Error(), `needs model_root in that module, model_root is in module "example.com/other"`) {
+		t.Errorf("generate() with model_root outside the module = %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const gormPackage = protogen.GoImportPath("gorm.io/gorm")
+
+// genRepository generates a GORM backed CRUD layer of a message, the message goes in and out
+// through Bind and Bundle, e.g CategoryRepository and NewCategoryRepository
+func (p *BimaPlugin) genRepository(g *protogen.GeneratedFile, m *protogen.Message, model protogen.GoIdent) {
+	if !p.walkModelFields(m.Desc, model) {
+		return
+	}
+	id := p.getIDField(m)
+	if id == nil {
+		return
+	}
+
+	idType, pointer := fieldGoType(g, id)
+	idValue := "id"
+	if pointer {
+		idValue = "&id"
+	}
+	ctx := g.QualifiedGoIdent(protogen.GoIdent{GoName: "Context", GoImportPath: "context"})
+	db := "*" + g.QualifiedGoIdent(gormPackage.Ident("DB"))
+	offset := "*" + g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       "Offset",
+		GoImportPath: "github.com/crowdeco/protoc-gen-bima/paginator",
+	})
+	iface := m.GoIdent.GoName + "Repository"
+	impl := strcase.ToLowerCamel(m.GoIdent.GoName) + "Repository"
+
+	g.P("type ", iface, " interface {")
+	g.P("Create(ctx ", ctx, ", x *", m.GoIdent, ") error")
+	g.P("Update(ctx ", ctx, ", x *", m.GoIdent, ") error")
+	g.P("Delete(ctx ", ctx, ", id ", idType, ") error")
+	g.P("FindByID(ctx ", ctx, ", id ", idType, ") (*", m.GoIdent, ", error)")
+	g.P("Paginate(ctx ", ctx, ", page int, limit int) ([]*", m.GoIdent, ", ", offset, ", error)")
+	g.P("}")
+	g.P()
+
+	g.P("type ", impl, " struct {")
+	g.P("db ", db)
+	g.P("}")
+	g.P()
+
+	g.P("func New", iface, "(db ", db, ") ", iface, " {")
+	g.P("return &", impl, "{db: db}")
+	g.P("}")
+	g.P()
+
+	// * the model is written back, so defaults and hooks reach the message
+	g.P("func (r *", impl, ") Create(ctx ", ctx, ", x *", m.GoIdent, ") error {")
+	g.P("v := ", model, "{}")
+	g.P("if err := x.Bind(&v); err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("if err := r.db.WithContext(ctx).Create(&v).Error; err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("return x.Bundle(&v)")
+	g.P("}")
+	g.P()
+
+	// * only the columns the message binds are updated, readonly ones like created_at are left as is,
+	// and so are the ones of fields left unset, e.g a nil timestamp or wrapper
+	always, unset := p.boundColumns(m, model, id)
+	g.P("func (r *", impl, ") Update(ctx ", ctx, ", x *", m.GoIdent, ") error {")
+	g.P("if ", zeroCondition(id, "x.Get"+id.GoName+"()"), " {")
+	g.P("return ", gormPackage.Ident("ErrRecordNotFound"))
+	g.P("}")
+	g.P("v := ", model, "{}")
+	g.P("if err := x.Bind(&v); err != nil {")
+	g.P("return err")
+	g.P("}")
+	if len(always) > 0 || len(unset) > 0 {
+		if len(always) > 0 {
+			g.P("columns := []string{", strings.Join(always, ", "), "}")
+		} else {
+			g.P("var columns []string")
+		}
+		for _, c := range unset {
+			g.P("if x.", c.field.GoName, " != nil {")
+			g.P("columns = append(columns, ", c.name, ")")
+			g.P("}")
+		}
+		g.P("if len(columns) > 0 {")
+		g.P("if err := r.db.WithContext(ctx).Model(&v).Select(columns).Updates(&v).Error; err != nil {")
+		g.P("return err")
+		g.P("}")
+		g.P("}")
+	}
+	// * RowsAffected counts changed rows only on some drivers, so a missing row is told by First
+	g.P("if err := r.db.WithContext(ctx).First(&v).Error; err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("return x.Bundle(&v)")
+	g.P("}")
+	g.P()
+
+	// * the primary key is set through Bind, GORM then builds the condition from it. A zero id
+	// would leave GORM without a condition, so it's never found.
+	g.P("func (r *", impl, ") Delete(ctx ", ctx, ", id ", idType, ") error {")
+	g.P("if ", zeroCondition(id, "id"), " {")
+	g.P("return ", gormPackage.Ident("ErrRecordNotFound"))
+	g.P("}")
+	g.P("v := ", model, "{}")
+	g.P("if err := (&", m.GoIdent, "{", id.GoName, ": ", idValue, "}).Bind(&v); err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("db := r.db.WithContext(ctx).Delete(&v)")
+	g.P("if db.Error != nil {")
+	g.P("return db.Error")
+	g.P("}")
+	g.P("if db.RowsAffected == 0 {")
+	g.P("return ", gormPackage.Ident("ErrRecordNotFound"))
+	g.P("}")
+	g.P("return nil")
+	g.P("}")
+	g.P()
+
+	g.P("func (r *", impl, ") FindByID(ctx ", ctx, ", id ", idType, ") (*", m.GoIdent, ", error) {")
+	g.P("if ", zeroCondition(id, "id"), " {")
+	g.P("return nil, ", gormPackage.Ident("ErrRecordNotFound"))
+	g.P("}")
+	g.P("v := ", model, "{}")
+	g.P("if err := (&", m.GoIdent, "{", id.GoName, ": ", idValue, "}).Bind(&v); err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	g.P("if err := r.db.WithContext(ctx).First(&v).Error; err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	g.P("x := &", m.GoIdent, "{}")
+	g.P("return x, x.Bundle(&v)")
+	g.P("}")
+	g.P()
+
+	g.P("func (r *", impl, ") Paginate(ctx ", ctx, ", page int, limit int) ([]*", m.GoIdent, ", ", offset, ", error) {")
+	g.P("var total int64")
+	g.P("if err := r.db.WithContext(ctx).Model(&", model, "{}).Count(&total).Error; err != nil {")
+	g.P("return nil, nil, err")
+	g.P("}")
+	g.P("pg := ", g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       "NewOffset",
+		GoImportPath: "github.com/crowdeco/protoc-gen-bima/paginator",
+	}), "(page, limit, total)")
+	// * a limit of 0 lists every row
+	g.P("db := r.db.WithContext(ctx)")
+	g.P("if pg.Limit() > 0 {")
+	g.P("db = db.Offset(pg.Offset()).Limit(pg.Limit())")
+	g.P("}")
+	g.P("var vs []", model)
+	g.P("if err := db.Find(&vs).Error; err != nil {")
+	g.P("return nil, nil, err")
+	g.P("}")
+	g.P("xs := make([]*", m.GoIdent, ", len(vs))")
+	g.P("for i := range vs {")
+	g.P("xs[i] = &", m.GoIdent, "{}")
+	g.P("if err := xs[i].Bundle(&vs[i]); err != nil {")
+	g.P("return nil, nil, err")
+	g.P("}")
+	g.P("}")
+	g.P("return xs, pg, nil")
+	g.P("}")
+	g.P()
+}
+
+// boundColumn is a quoted model field Update selects only when its proto field is set
+type boundColumn struct {
+	field *protogen.Field
+	name  string
+}
+
+// boundColumns returns the quoted model fields Bind sets from the message, but the primary key,
+// relations and the columns gorm sets itself on create and delete. Columns of fields having
+// presence, e.g messages and optional scalars, are returned apart as they may be left unset.
+func (p *BimaPlugin) boundColumns(m *protogen.Message, model protogen.GoIdent, id *protogen.Field) (always []string, unset []boundColumn) {
+	sf := p.modelTypes[model.GoName]
+	seen := map[string]bool{}
+	for _, field := range m.Fields {
+		if field == id {
+			continue
+		}
+		f, ok := getModelField(sf, field, false)
+		if !ok || seen[f.Name()] || isRelation(f.Type()) || f.managed() {
+			continue
+		}
+		seen[f.Name()] = true
+		name := strconv.Quote(f.Name())
+		// * oneof members are always selected, so switching the oneof clears the others
+		if field.Desc.HasPresence() && !isOneofField(field) {
+			unset = append(unset, boundColumn{field: field, name: name})
+		} else {
+			always = append(always, name)
+		}
+	}
+	return always, unset
+}
+
+// zeroCondition renders a condition telling whether a scalar field's value is its zero value
+func zeroCondition(field *protogen.Field, name string) string {
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return name + " == \"\""
+	case protoreflect.BytesKind:
+		return "len(" + name + ") == 0"
+	case protoreflect.BoolKind:
+		return "!" + name
+	}
+	return name + " == 0"
+}
+
+// getIDField returns the scalar id field Bind sets the model's primary key from
+func (p *BimaPlugin) getIDField(m *protogen.Message) *protogen.Field {
+	model, _ := getModelIdent(m.Desc)
+	for _, field := range m.Fields {
+		if field.Desc.Name() != "id" {
+			continue
+		}
+		if field.Desc.IsList() || field.Desc.IsMap() || field.Message != nil || isOneofField(field) {
+			p.diagnose(severityWarning, field.Desc, token.NoPos, "repository of %s needs a scalar id field, skipping", m.Desc.FullName())
+			return nil
+		}
+		if _, ok := getModelField(p.modelTypes[model.GoName], field, false); !ok {
+			p.diagnose(severityWarning, field.Desc, token.NoPos, "repository of %s needs the id field bound to model %s, skipping", m.Desc.FullName(), model.GoName)
+			return nil
+		}
+		return field
+	}
+	p.diagnose(severityWarning, m.Desc, token.NoPos, "repository of %s needs an id field, skipping", m.Desc.FullName())
+	return nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gorm "github.com/crowdeco/protoc-gen-bima/options"
+	"github.com/iancoleman/strcase"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// * CRUD actions a handler is generated for
+const (
+	actionCreate = "create"
+	actionGet    = "get"
+	actionUpdate = "update"
+	actionDelete = "delete"
+	actionList   = "list"
+)
+
+// * actions guessed from rpc names without (gorm.method), e.g CreateCategory
+var actionPrefixes = []struct{ prefix, action string }{
+	{"Create", actionCreate},
+	{"Get", actionGet},
+	{"Find", actionGet},
+	{"Update", actionUpdate},
+	{"Delete", actionDelete},
+	{"List", actionList},
+}
+
+var methodActions = map[gorm.GormMethodOptions_Action]string{
+	gorm.GormMethodOptions_CREATE: actionCreate,
+	gorm.GormMethodOptions_GET:    actionGet,
+	gorm.GormMethodOptions_UPDATE: actionUpdate,
+	gorm.GormMethodOptions_DELETE: actionDelete,
+	gorm.GormMethodOptions_LIST:   actionList,
+}
+
+// handler of an rpc served by a message's repository
+type handler struct {
+	action   string
+	status   string            // * status of the success response, e.g StatusCreated
+	message  *protogen.Message // * message with (gorm.opts) the response carries
+	response *protogen.Message
+	id       *protogen.Field // * request's id field, on get and delete, optional ones are dereferenced
+	page     *protogen.Field // * request's page and limit fields, on list
+	limit    *protogen.Field
+}
+
+func (h handler) repository() protogen.GoIdent {
+	return protogen.GoIdent{
+		GoName:       h.message.GoIdent.GoName + "Repository",
+		GoImportPath: h.message.GoIdent.GoImportPath,
+	}
+}
+
+// helper returns the response helper of a status, e.g CategoryResponseStatusOK
+func (h handler) helper(name string) protogen.GoIdent {
+	return protogen.GoIdent{
+		GoName:       h.response.GoIdent.GoName + name,
+		GoImportPath: h.response.GoIdent.GoImportPath,
+	}
+}
+
+// genServer generates a struct implementing the service's server, rpcs a handler is found for are
+// served by repositories, others are Unimplemented, e.g CategoryServiceHandler
+func (p *BimaPlugin) genServer(g *protogen.GeneratedFile, s *protogen.Service) {
+	name := s.GoName + "Handler"
+	handlers := make(map[*protogen.Method]handler)
+	var messages []*protogen.Message
+	for _, method := range s.Methods {
+		h, ok := p.getHandler(method)
+		if !ok {
+			continue
+		}
+		handlers[method] = h
+		if !containsMessage(messages, h.message) {
+			messages = append(messages, h.message)
+		}
+	}
+
+	g.P("type ", name, " struct {")
+	g.P("Unimplemented", s.GoName, "Server")
+	for _, m := range messages {
+		g.P(m.GoIdent.GoName, "Repository ", handler{message: m}.repository())
+	}
+	g.P("}")
+	g.P()
+
+	params := make([]string, len(messages))
+	for i, m := range messages {
+		params[i] = strcase.ToLowerCamel(m.GoIdent.GoName) + "Repository " + g.QualifiedGoIdent(handler{message: m}.repository())
+	}
+	g.P("func New", name, "(", strings.Join(params, ", "), ") *", name, " {")
+	g.P("return &", name, "{")
+	for _, m := range messages {
+		g.P(m.GoIdent.GoName, "Repository: ", strcase.ToLowerCamel(m.GoIdent.GoName), "Repository,")
+	}
+	g.P("}")
+	g.P("}")
+	g.P()
+	g.P("var _ ", s.GoName, "Server = (*", name, ")(nil)")
+	g.P()
+
+	for _, method := range s.Methods {
+		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+			continue // * served by the embedded Unimplemented server
+		}
+		g.P("func (h *", name, ") ", method.GoName, "(ctx ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "Context", GoImportPath: "context"}),
+			", in *", method.Input.GoIdent, ") (*", method.Output.GoIdent, ", error) {")
+		if h, ok := handlers[method]; ok {
+			p.genHandlerBody(g, h)
+		} else {
+			g.P("return nil, ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "Errorf", GoImportPath: "google.golang.org/grpc/status"}),
+				"(", g.QualifiedGoIdent(protogen.GoIdent{GoName: "Unimplemented", GoImportPath: "google.golang.org/grpc/codes"}),
+				", \"method ", method.GoName, " not implemented\")")
+		}
+		g.P("}")
+		g.P()
+	}
+}
+
+// getHandler tells how an rpc is served, its action is declared by (gorm.method) or guessed from its
+// name, and the response must carry a message with a repository, e.g CreateCategory(Category) returns (CategoryResponse).
+// Rpcs declaring an action they can't be served with fail the generation.
+func (p *BimaPlugin) getHandler(method *protogen.Method) (handler, bool) {
+	h := handler{response: method.Output, status: "StatusOK"}
+	idField := protoreflect.Name("id")
+	opts := getMethodOptions(method.Desc)
+	if opts != nil {
+		h.action = methodActions[opts.GetAction()]
+		idField = protoreflect.Name(opts.GetIdField())
+		switch h.action {
+		case actionCreate:
+			h.status = "StatusCreated"
+		case actionDelete:
+			h.status = "StatusNoContent"
+		}
+	} else {
+		for _, a := range actionPrefixes {
+			if strings.HasPrefix(method.GoName, a.prefix) {
+				h.action = a.action
+				break
+			}
+		}
+	}
+	if h.action == "" {
+		return handler{}, false
+	}
+	reject := func(format string, args ...interface{}) (handler, bool) {
+		sev := severityWarning
+		if opts != nil {
+			sev = severityError
+		}
+		p.diagnose(sev, method.Desc, token.NoPos, "rpc %s can't be served by a repository, "+format, append([]interface{}{method.GoName}, args...)...)
+		return handler{}, false
+	}
+	if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+		if opts != nil {
+			return reject("streaming rpcs can't be generated")
+		}
+		return handler{}, false
+	}
+
+	suffix := regexp.QuoteMeta(p.responseSuffix) + `$`
+	if h.action == actionList && opts == nil {
+		suffix = `Paginated` + suffix
+	}
+	if !regexp.MustCompile(suffix).MatchString(h.response.GoIdent.GoName) {
+		if opts != nil {
+			return reject("%s doesn't end with %s", h.response.Desc.FullName(), p.responseSuffix)
+		}
+		return handler{}, false
+	}
+	for _, field := range h.response.Fields {
+		if field.Desc.Name() == "data" && field.Message != nil && field.Desc.IsList() == (h.action == actionList) {
+			h.message = field.Message
+		}
+	}
+	if h.message == nil || !p.hasRepository(h.message) {
+		if opts != nil {
+			return reject("%s has no data field of a message with a repository", h.response.Desc.FullName())
+		}
+		return handler{}, false
+	}
+
+	switch h.action {
+	case actionCreate, actionUpdate:
+		if method.Input != h.message {
+			if opts != nil {
+				return reject("its request must be %s", h.message.Desc.FullName())
+			}
+			return handler{}, false
+		}
+	case actionGet, actionDelete:
+		id := p.getIDField(h.message)
+		for _, field := range method.Input.Fields {
+			if field.Desc.Name() == idField && field.Desc.Kind() == id.Desc.Kind() && field.Enum == id.Enum &&
+				!field.Desc.IsList() && !isOneofField(field) {
+				h.id = field
+			}
+		}
+		if h.id == nil {
+			return reject("%s has no %s field like %s's %s", method.Input.Desc.FullName(), idField, h.message.Desc.FullName(), id.Desc.Name())
+		}
+	case actionList:
+		for _, field := range method.Input.Fields {
+			if field.Desc.IsList() || field.Desc.HasPresence() || !isIntegerKind(field.Desc.Kind()) {
+				continue
+			}
+			switch field.Desc.Name() {
+			case "page":
+				h.page = field
+			case "limit", "per_page", "page_size":
+				h.limit = field
+			}
+		}
+	}
+
+	if !p.hasResponseStatus(h.response, h.status) {
+		return reject("%s has no %s helper", h.response.Desc.FullName(), h.status)
+	}
+	return h, true
+}
+
+// hasRepository tells whether a repository is generated for the message
+func (p *BimaPlugin) hasRepository(m *protogen.Message) bool {
+	model, ok := getModelIdent(m.Desc)
+	return ok && p.walkModelFields(m.Desc, model) && p.getIDField(m) != nil
+}
+
+func (p *BimaPlugin) hasResponseStatus(response *protogen.Message, name string) bool {
+	for _, s := range p.responseStatuses(response) {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *BimaPlugin) genHandlerBody(g *protogen.GeneratedFile, h handler) {
+	repository := "h." + h.message.GoIdent.GoName + "Repository"
+	switch h.action {
+	case actionCreate, actionUpdate:
+		g.P("if err := ", repository, ".", strcase.ToCamel(h.action), "(ctx, in); err != nil {")
+		p.genHandlerError(g, h)
+		g.P("}")
+		g.P("return ", h.helper(h.status), "(in)")
+	case actionGet:
+		id := p.genHandlerID(g, h)
+		g.P("x, err := ", repository, ".FindByID(ctx, ", id, ")")
+		g.P("if err != nil {")
+		p.genHandlerError(g, h)
+		g.P("}")
+		g.P("return ", h.helper(h.status), "(x)")
+	case actionDelete:
+		id := p.genHandlerID(g, h)
+		g.P("if err := ", repository, ".Delete(ctx, ", id, "); err != nil {")
+		p.genHandlerError(g, h)
+		g.P("}")
+		if h.status == "StatusNoContent" {
+			g.P("return ", h.helper(h.status), "()")
+		} else {
+			g.P("return ", h.helper(h.status), "(nil)")
+		}
+	case actionList:
+		page, limit := "1", "0"
+		if h.page != nil {
+			page = "int(in." + h.page.GoName + ")"
+		}
+		if h.limit != nil {
+			limit = "int(in." + h.limit.GoName + ")"
+		}
+		if getPaginationField(h.response) == nil {
+			g.P("xs, _, err := ", repository, ".Paginate(ctx, ", page, ", ", limit, ")")
+		} else {
+			g.P("xs, pg, err := ", repository, ".Paginate(ctx, ", page, ", ", limit, ")")
+		}
+		g.P("if err != nil {")
+		p.genHandlerError(g, h)
+		g.P("}")
+		if getPaginationField(h.response) == nil {
+			g.P("return ", h.helper(h.status), "(xs)")
+		} else {
+			g.P("return ", h.helper(h.status+"WithPaginator"), "(xs, pg)")
+		}
+	}
+}
+
+// genHandlerID returns the request's id the repository is called with, an optional id is
+// dereferenced once it's checked to be set, an unset one responds with StatusBadRequest or InvalidArgument
+func (p *BimaPlugin) genHandlerID(g *protogen.GeneratedFile, h handler) string {
+	if !h.id.Desc.HasPresence() {
+		return "in." + h.id.GoName
+	}
+	g.P("if in.", h.id.GoName, " == nil {")
+	msg := strconv.Quote(string(h.id.Desc.Name()) + " is required")
+	if p.hasResponseStatus(h.response, "StatusBadRequest") {
+		g.P("return ", h.helper("StatusBadRequest"), "(nil, ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "New", GoImportPath: "errors"}), "(", msg, "))")
+	} else {
+		g.P("return nil, ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "Error", GoImportPath: "google.golang.org/grpc/status"}),
+			"(", g.QualifiedGoIdent(protogen.GoIdent{GoName: "InvalidArgument", GoImportPath: "google.golang.org/grpc/codes"}), ", ", msg, ")")
+	}
+	g.P("}")
+	return "*in." + h.id.GoName
+}
+
+// genHandlerError responds with StatusNotFound on gorm.ErrRecordNotFound and StatusInternalServerError
+// otherwise, the error is returned as is when the response has no such helpers
+func (p *BimaPlugin) genHandlerError(g *protogen.GeneratedFile, h handler) {
+	if h.action != actionCreate && h.action != actionList && p.hasResponseStatus(h.response, "StatusNotFound") {
+		g.P("if ", g.QualifiedGoIdent(protogen.GoIdent{GoName: "Is", GoImportPath: "errors"}),
+			"(err, ", gormPackage.Ident("ErrRecordNotFound"), ") {")
+		g.P("return ", h.helper("StatusNotFound"), "(nil, err)")
+		g.P("}")
+	}
+	if p.hasResponseStatus(h.response, "StatusInternalServerError") {
+		g.P("return ", h.helper("StatusInternalServerError"), "(nil, err)")
+		return
+	}
+	g.P("return nil, err")
+}
+
+func containsMessage(messages []*protogen.Message, m *protogen.Message) bool {
+	for _, message := range messages {
+		if message == m {
+			return true
+		}
+	}
+	return false
+}
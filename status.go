@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// * net/http's status constants
+var httpStatuses = map[string]int{
+	"StatusContinue":           100,
+	"StatusSwitchingProtocols": 101,
+	"StatusProcessing":         102,
+	"StatusEarlyHints":         103,
+
+	"StatusOK":                   200,
+	"StatusCreated":              201,
+	"StatusAccepted":             202,
+	"StatusNonAuthoritativeInfo": 203,
+	"StatusNoContent":            204,
+	"StatusResetContent":         205,
+	"StatusPartialContent":       206,
+	"StatusMultiStatus":          207,
+	"StatusAlreadyReported":      208,
+	"StatusIMUsed":               226,
+
+	"StatusMultipleChoices":   300,
+	"StatusMovedPermanently":  301,
+	"StatusFound":             302,
+	"StatusSeeOther":          303,
+	"StatusNotModified":       304,
+	"StatusUseProxy":          305,
+	"StatusTemporaryRedirect": 307,
+	"StatusPermanentRedirect": 308,
+
+	"StatusBadRequest":                   400,
+	"StatusUnauthorized":                 401,
+	"StatusPaymentRequired":              402,
+	"StatusForbidden":                    403,
+	"StatusNotFound":                     404,
+	"StatusMethodNotAllowed":             405,
+	"StatusNotAcceptable":                406,
+	"StatusProxyAuthRequired":            407,
+	"StatusRequestTimeout":               408,
+	"StatusConflict":                     409,
+	"StatusGone":                         410,
+	"StatusLengthRequired":               411,
+	"StatusPreconditionFailed":           412,
+	"StatusRequestEntityTooLarge":        413,
+	"StatusRequestURITooLong":            414,
+	"StatusUnsupportedMediaType":         415,
+	"StatusRequestedRangeNotSatisfiable": 416,
+	"StatusExpectationFailed":            417,
+	"StatusTeapot":                       418,
+	"StatusMisdirectedRequest":           421,
+	"StatusUnprocessableEntity":          422,
+	"StatusLocked":                       423,
+	"StatusFailedDependency":             424,
+	"StatusTooEarly":                     425,
+	"StatusUpgradeRequired":              426,
+	"StatusPreconditionRequired":         428,
+	"StatusTooManyRequests":              429,
+	"StatusRequestHeaderFieldsTooLarge":  431,
+	"StatusUnavailableForLegalReasons":   451,
+
+	"StatusInternalServerError":           500,
+	"StatusNotImplemented":                501,
+	"StatusBadGateway":                    502,
+	"StatusServiceUnavailable":            503,
+	"StatusGatewayTimeout":                504,
+	"StatusHTTPVersionNotSupported":       505,
+	"StatusVariantAlsoNegotiates":         506,
+	"StatusInsufficientStorage":           507,
+	"StatusLoopDetected":                  508,
+	"StatusNotExtended":                   510,
+	"StatusNetworkAuthenticationRequired": 511,
+}
+
+// * gRPC codes of error statuses, the reverse of grpc-gateway's mapping
+var grpcCodes = map[int]string{
+	400: "InvalidArgument",
+	401: "Unauthenticated",
+	403: "PermissionDenied",
+	404: "NotFound",
+	408: "DeadlineExceeded",
+	409: "AlreadyExists",
+	412: "FailedPrecondition",
+	422: "InvalidArgument",
+	429: "ResourceExhausted",
+	499: "Canceled",
+	500: "Internal",
+	501: "Unimplemented",
+	503: "Unavailable",
+	504: "DeadlineExceeded",
+}
+
+var defaultStatuses = []string{
+	"StatusOK", "StatusCreated", "StatusNoContent",
+	"StatusBadRequest", "StatusNotFound", "StatusInternalServerError",
+}
+
+// status of a generated response helper, e.g ProductResponseStatusCreated
+type status struct {
+	name   string
+	code   int
+	custom bool // * not declared by net/http
+}
+
+func newStatus(name string) (status, error) {
+	code, ok := httpStatuses[name]
+	if !ok {
+		return status{}, errors.New(fmt.Sprintf("unknown status %s", name))
+	}
+	return status{name: name, code: code}, nil
+}
+
+func newCustomStatus(code int, name string) (status, error) {
+	if code < 100 || code > 599 {
+		return status{}, errors.New(fmt.Sprintf("invalid code %d of status %s", code, name))
+	}
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return status{}, errors.New(fmt.Sprintf("invalid status name %q", name))
+	}
+	return status{name: name, code: code, custom: true}, nil
+}
+
+// parseStatus parses a net/http constant, e.g StatusConflict, or a custom code:name pair, e.g 499:StatusClientClosed
+func parseStatus(str string) (status, error) {
+	i := strings.Index(str, ":")
+	if i < 0 {
+		return newStatus(str)
+	}
+	code, err := strconv.Atoi(str[:i])
+	if err != nil {
+		return status{}, errors.New(fmt.Sprintf("invalid code of status %s", str))
+	}
+	return newCustomStatus(code, str[i+1:])
+}
+
+// codeValue renders the status' code in generated code
+func (s status) codeValue(g *protogen.GeneratedFile) string {
+	if s.custom {
+		return strconv.Itoa(s.code)
+	}
+	return g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       s.name,
+		GoImportPath: "net/http",
+	})
+}
+
+// grpcCode renders the gRPC code matching the status in generated code
+func (s status) grpcCode(g *protogen.GeneratedFile) string {
+	name, ok := grpcCodes[s.code]
+	if !ok {
+		name = "Unknown"
+	}
+	return g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       name,
+		GoImportPath: "google.golang.org/grpc/codes",
+	})
+}
+
+// ok tells whether the helper responds with data rather than an error
+func (s status) ok() bool {
+	return s.code < 400
+}
+
+// hasData tells whether the helper's response carries data
+func (s status) hasData() bool {
+	return s.code != 204
+}
+
+// statusList is a repeatable plugin parameter, e.g statuses=StatusOK,statuses=499:StatusClientClosed
+type statusList []status
+
+func (l *statusList) String() string {
+	names := make([]string, len(*l))
+	for i, s := range *l {
+		names[i] = s.name
+	}
+	return strings.Join(names, ",")
+}
+
+func (l *statusList) Set(str string) error {
+	s, err := parseStatus(str)
+	if err != nil {
+		return err
+	}
+	// * a helper is declared per status name, whatever its code
+	for _, prev := range *l {
+		if prev.name == s.name {
+			return errors.New(fmt.Sprintf("status %s is declared twice", s.name))
+		}
+	}
+	*l = append(*l, s)
+	return nil
+}
+
+// responseStatuses returns the status helpers of a response message, taken from its
+// (gorm.response) option, its file's (gorm.file_response) or the statuses parameter
+func (p *BimaPlugin) responseStatuses(msg *protogen.Message) []status {
+	opts := getResponseOptions(msg.Desc)
+	if opts == nil {
+		return p.statuses
+	}
+
+	var statuses []status
+	names := map[string]bool{}
+	add := func(s status, err error) {
+		if err != nil {
+			p.diagnose(severityError, msg.Desc, token.NoPos, "%s", err)
+			return
+		}
+		if names[s.name] {
+			p.diagnose(severityError, msg.Desc, token.NoPos, "status %s is declared twice", s.name)
+			return
+		}
+		names[s.name] = true
+		statuses = append(statuses, s)
+	}
+	for _, name := range opts.GetStatuses() {
+		add(newStatus(name))
+	}
+	for _, custom := range opts.GetCustom() {
+		add(newCustomStatus(int(custom.GetCode()), custom.GetName()))
+	}
+	return statuses
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    status
+		wantErr bool
+	}{
+		{str: "StatusOK", want: status{name: "StatusOK", code: 200}},
+		{str: "StatusConflict", want: status{name: "StatusConflict", code: 409}},
+		{str: "499:StatusClientClosed", want: status{name: "StatusClientClosed", code: 499, custom: true}},
+		{str: "StatusNope", wantErr: true},
+		{str: "abc:StatusClientClosed", wantErr: true},
+		{str: "99:StatusTooLow", wantErr: true},
+		{str: "600:StatusTooHigh", wantErr: true},
+		{str: "499:statusClientClosed", wantErr: true},
+		{str: "499:Status-Closed", wantErr: true},
+		{str: "499:", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseStatus(tt.str)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStatus(%q) error = %v, wantErr %v", tt.str, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseStatus(%q) = %+v, want %+v", tt.str, got, tt.want)
+		}
+	}
+}
+
+func TestStatusListSet(t *testing.T) {
+	tests := []struct {
+		strs    []string
+		wantErr bool
+	}{
+		{strs: []string{"StatusOK", "StatusCreated", "499:StatusClientClosed"}},
+		{strs: []string{"StatusOK", "StatusOK"}, wantErr: true},
+		{strs: []string{"499:StatusClientClosed", "498:StatusClientClosed"}, wantErr: true},
+		{strs: []string{"StatusNotFound", "404:StatusNotFound"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		var l statusList
+		var err error
+		for _, str := range tt.strs {
+			if err = l.Set(str); err != nil {
+				break
+			}
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q) error = %v, wantErr %v", tt.strs, err, tt.wantErr)
+		}
+		if err != nil && len(l) != len(tt.strs)-1 {
+			t.Errorf("Set(%q) kept %d statuses, want %d", tt.strs, len(l), len(tt.strs)-1)
+		}
+	}
+}